@@ -0,0 +1,41 @@
+package bearer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const scheme = "Bearer"
+
+type contextKey struct{}
+
+// Retrieve the claims from a request context created by the middleware.
+//
+// Returns false if the context does not have claims of type T.
+func FromContext[T any](ctx context.Context) (T, bool) {
+	claims, ok := ctx.Value(contextKey{}).(T)
+	return claims, ok
+}
+
+func newContext[T any](ctx context.Context, claims T) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// Retrieve the bearer token from the Authorization header of a request.
+//
+// Returns false if the request does not have an Authorization header, and an empty token if the header does not
+// use the bearer scheme.
+func tokenFromRequest(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", false
+	}
+
+	// the scheme is case-insensitive
+	if len(header) <= len(scheme)+1 || !strings.EqualFold(header[:len(scheme)], scheme) || header[len(scheme)] != ' ' {
+		return "", true
+	}
+
+	return strings.TrimSpace(header[len(scheme)+1:]), true
+}
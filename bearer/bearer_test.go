@@ -0,0 +1,236 @@
+package bearer_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/bearer"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+var (
+	Password = pw.Raw{
+		Password: pw.Password{
+			String: "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword",
+		},
+	}
+)
+
+type Claims struct {
+	Service string `json:"service"`
+}
+
+func newSealer(t *testing.T, cfg iron.SealConfig) *iron.Sealer[Claims] {
+	cfg.Encryption = iron.DefaultEncryption
+	cfg.Integrity = iron.DefaultIntegrity
+
+	sealer, err := iron.NewSealer[Claims](Password, pw.UnsealRaw{}, cfg)
+	a.Equals(t, err, nil)
+
+	return sealer
+}
+
+func claimsHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := bearer.FromContext[Claims](r.Context())
+		a.Equals(t, ok, true)
+
+		_, _ = w.Write([]byte(claims.Service))
+	})
+}
+
+func request(h http.Handler, authorization string) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec.Result()
+}
+
+func TestTransportAndMiddleware(t *testing.T) {
+	t.Parallel()
+
+	sealer := newSealer(t, iron.SealConfig{TTL: 60000})
+
+	server := httptest.NewServer(bearer.Middleware(sealer, bearer.Config{Realm: "api"})(claimsHandler(t)))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &bearer.Transport[Claims]{
+			Sealer: sealer,
+			Claims: func(r *http.Request) (Claims, error) {
+				return Claims{Service: "billing"}, nil
+			},
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	a.Equals(t, err, nil)
+
+	res, err := client.Do(req)
+	a.Equals(t, err, nil)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	a.Equals(t, err, nil)
+
+	a.Equals(t, res.StatusCode, http.StatusOK)
+	a.Equals(t, string(body), "billing")
+	a.Equals(t, req.Header.Get("Authorization"), "")
+}
+
+func TestTransportFailsWhenClaimsFail(t *testing.T) {
+	t.Parallel()
+
+	claimsErr := errors.New("no claims")
+	reached := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &bearer.Transport[Claims]{
+			Sealer: newSealer(t, iron.SealConfig{}),
+			Claims: func(r *http.Request) (Claims, error) {
+				return Claims{}, claimsErr
+			},
+		},
+	}
+
+	_, err := client.Get(server.URL)
+	a.Equals(t, errors.Is(err, claimsErr), true)
+	a.Equals(t, reached, false)
+}
+
+func TestMiddlewareRejectsMissingToken(t *testing.T) {
+	t.Parallel()
+
+	h := bearer.Middleware(newSealer(t, iron.SealConfig{}), bearer.Config{Realm: "api"})(claimsHandler(t))
+
+	res := request(h, "")
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+	a.Equals(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="api"`)
+
+	h = bearer.Middleware(newSealer(t, iron.SealConfig{}), bearer.Config{})(claimsHandler(t))
+
+	res = request(h, "")
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+	a.Equals(t, res.Header.Get("WWW-Authenticate"), "Bearer")
+}
+
+func TestMiddlewareRejectsMalformedHeader(t *testing.T) {
+	t.Parallel()
+
+	h := bearer.Middleware(newSealer(t, iron.SealConfig{}), bearer.Config{Realm: `the "api"`})(claimsHandler(t))
+
+	for _, authorization := range []string{"Basic dXNlcjpwYXNz", "Bearer", "Bearer ", "Bearertoken"} {
+		res := request(h, authorization)
+		a.Equals(t, res.StatusCode, http.StatusBadRequest)
+		a.Equals(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="the \"api\"", error="invalid_request", error_description="The Authorization header is not a bearer token"`)
+	}
+}
+
+func TestMiddlewareRejectsTamperedToken(t *testing.T) {
+	t.Parallel()
+
+	sealer := newSealer(t, iron.SealConfig{})
+	h := bearer.Middleware(sealer, bearer.Config{Realm: "api"})(claimsHandler(t))
+
+	token, err := sealer.Seal(Claims{Service: "billing"})
+	a.Equals(t, err, nil)
+
+	parts := strings.Split(token, "*")
+	parts[4] = "A" + parts[4][1:]
+	if parts[4] == strings.Split(token, "*")[4] {
+		parts[4] = "B" + parts[4][1:]
+	}
+
+	res := request(h, "Bearer "+strings.Join(parts, "*"))
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+	a.Equals(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="api", error="invalid_token", error_description="The access token is invalid"`)
+
+	res = request(h, "bearer "+token)
+	a.Equals(t, res.StatusCode, http.StatusOK)
+}
+
+func TestMiddlewareRejectsExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	sealer := newSealer(t, iron.SealConfig{TTL: 60000, TimestampSkewSec: -1, Clock: fake})
+	h := bearer.Middleware(sealer, bearer.Config{Realm: "api"})(claimsHandler(t))
+
+	token, err := sealer.Seal(Claims{Service: "billing"})
+	a.Equals(t, err, nil)
+
+	res := request(h, "Bearer "+token)
+	a.Equals(t, res.StatusCode, http.StatusOK)
+
+	fake.Advance(time.Minute)
+
+	res = request(h, "Bearer "+token)
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+	a.Equals(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="api", error="invalid_token", error_description="The access token expired"`)
+}
+
+func TestFromContextWithoutClaims(t *testing.T) {
+	t.Parallel()
+
+	_, ok := bearer.FromContext[Claims](httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	a.Equals(t, ok, false)
+}
+
+func TestMiddlewareRejectsClaimsPastMaxAge(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	sealer, err := iron.NewSealer[iron.Claims[Claims]](Password, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption:       iron.DefaultEncryption,
+		Integrity:        iron.DefaultIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+		Claims:           &iron.ClaimsExpectation{MaxAge: time.Minute},
+	})
+	a.Equals(t, err, nil)
+
+	h := bearer.Middleware(sealer, bearer.Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	token, err := sealer.Seal(iron.Claims[Claims]{Data: Claims{Service: "billing"}})
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Minute)
+
+	res := request(h, "Bearer "+token)
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+	a.Equals(t, res.Header.Get("WWW-Authenticate"), `Bearer error="invalid_token", error_description="The access token expired"`)
+}
+
+func TestMiddlewareRejectsNullClaims(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{Encryption: iron.DefaultEncryption, Integrity: iron.DefaultIntegrity}
+	sealer, err := iron.NewSealer[*iron.Claims[Claims]](Password, pw.UnsealRaw{}, cfg)
+	a.Equals(t, err, nil)
+
+	h := bearer.Middleware(sealer, bearer.Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	token, err := iron.Seal[*Claims](nil, Password, cfg)
+	a.Equals(t, err, nil)
+
+	res := request(h, "Bearer "+token)
+	a.Equals(t, res.StatusCode, http.StatusUnauthorized)
+}
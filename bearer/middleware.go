@@ -0,0 +1,82 @@
+package bearer
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+)
+
+// Config options for the middleware.
+type Config struct {
+	// Realm sent in the WWW-Authenticate header of rejected requests.
+	Realm string
+}
+
+// Wrap a handler so requests must have a bearer token sealed with the sealer, with its claims in the request context,
+// retrieved with FromContext.
+//
+// Requests without a valid token are rejected with a 401 Unauthorized response and a WWW-Authenticate header, as
+// described in RFC 6750. A malformed Authorization header is rejected with a 400 Bad Request response.
+func Middleware[T any](sealer *iron.Sealer[T], cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := tokenFromRequest(r)
+			if !ok {
+				cfg.challenge(w, http.StatusUnauthorized, "", "")
+				return
+			}
+			if token == "" {
+				cfg.challenge(w, http.StatusBadRequest, "invalid_request", "The Authorization header is not a bearer token")
+				return
+			}
+
+			claims, err := sealer.UnsealContext(r.Context(), token)
+			if err != nil {
+				if isExpired(err) {
+					cfg.challenge(w, http.StatusUnauthorized, "invalid_token", "The access token expired")
+				} else {
+					cfg.challenge(w, http.StatusUnauthorized, "invalid_token", "The access token is invalid")
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(newContext(r.Context(), claims)))
+		})
+	}
+}
+
+// Reject a request with a WWW-Authenticate header for the error.
+func (cfg Config) challenge(w http.ResponseWriter, statusCode int, code string, description string) {
+	var params []string
+	if cfg.Realm != "" {
+		params = append(params, "realm="+quote(cfg.Realm))
+	}
+	if code != "" {
+		params = append(params, "error="+quote(code))
+	}
+	if description != "" {
+		params = append(params, "error_description="+quote(description))
+	}
+
+	challenge := scheme
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(statusCode), statusCode)
+}
+
+// quote a value for an auth-param
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// check whether a token was rejected for being too old, rather than for being invalid
+func isExpired(err error) bool {
+	return errors.Is(err, ironerrors.ErrExpiredSeal) || errors.Is(err, ironerrors.ErrMaxAgeExceeded) ||
+		errors.Is(err, ironerrors.ErrClaimsTooOld)
+}
@@ -0,0 +1,54 @@
+package bearer
+
+import (
+	"net/http"
+
+	"github.com/iron-auth/iron-crypto"
+)
+
+// Round tripper that seals claims for each request and sends them as a bearer token in the Authorization header.
+type Transport[T any] struct {
+	// Sealer for the claims.
+	Sealer *iron.Sealer[T]
+	// Create the claims for a request. Returning an error stops the request from being sent.
+	Claims func(r *http.Request) (T, error)
+	// Round tripper that sends the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// Send a request with a bearer token for its claims.
+//
+// The request is not modified, as the token is added to a copy of it.
+func (t *Transport[T]) RoundTrip(r *http.Request) (*http.Response, error) {
+	claims, err := t.Claims(r)
+	if err != nil {
+		closeBody(r)
+		return nil, err
+	}
+
+	token, err := t.Sealer.SealContext(r.Context(), claims)
+	if err != nil {
+		closeBody(r)
+		return nil, err
+	}
+
+	authorized := r.Clone(r.Context())
+	authorized.Header.Set("Authorization", scheme+" "+token)
+
+	return t.base().RoundTrip(authorized)
+}
+
+func (t *Transport[T]) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+
+	return t.Base
+}
+
+// a round tripper must close the request body, even when it returns an error
+func closeBody(r *http.Request) {
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+}
@@ -2,6 +2,9 @@ package bits
 
 import (
 	"bytes"
+	"crypto/subtle"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
 )
 
 // Pad the given message to the given block size.
@@ -12,10 +15,36 @@ func Pad(message []byte, blockSize int) []byte {
 	return append(message, text...)
 }
 
-// Unpad the given message.
-func Unpad(message []byte) []byte {
+// Unpad the given message padded to the given block size, checking the padding is valid.
+//
+// The padding bytes are checked in constant time, so the time taken does not reveal where invalid padding starts.
+func Unpad(message []byte, blockSize int) ([]byte, error) {
 	messageLength := len(message)
+	if messageLength == 0 {
+		return nil, ironerrors.ErrInvalidPadding
+	}
+
 	paddingLength := int(message[messageLength-1])
+	valid := subtle.ConstantTimeLessOrEq(1, paddingLength) &
+		subtle.ConstantTimeLessOrEq(paddingLength, blockSize) &
+		subtle.ConstantTimeLessOrEq(paddingLength, messageLength)
+
+	// check every byte that could be padding, whatever the padding length is
+	checkLength := blockSize
+	if checkLength > messageLength {
+		checkLength = messageLength
+	}
+
+	for i := 1; i <= checkLength; i++ {
+		isPadding := subtle.ConstantTimeLessOrEq(i, paddingLength)
+		matches := subtle.ConstantTimeByteEq(message[messageLength-i], byte(paddingLength))
+
+		valid &= subtle.ConstantTimeSelect(isPadding, matches, 1)
+	}
+
+	if valid != 1 {
+		return nil, ironerrors.ErrInvalidPadding
+	}
 
-	return message[:messageLength-paddingLength]
+	return message[:messageLength-paddingLength], nil
 }
@@ -1,9 +1,11 @@
 package bits_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/iron-auth/iron-crypto/bits"
+	"github.com/iron-auth/iron-crypto/ironerrors"
 	a "github.com/james-elicx/go-utils/assert"
 )
 
@@ -20,14 +22,42 @@ func TestUnpad(t *testing.T) {
 	t.Parallel()
 
 	padded := bits.Pad([]byte{0x01, 0x02, 0x03}, 8)
-	unpadded := bits.Unpad(padded)
+	unpadded, err := bits.Unpad(padded, 8)
+	a.Equals(t, err, nil)
 	a.EqualsArray(t, unpadded, []byte{0x01, 0x02, 0x03})
 
 	padded = bits.Pad([]byte{0x01, 0x02, 0x03}, 1)
-	unpadded = bits.Unpad(padded)
+	unpadded, err = bits.Unpad(padded, 1)
+	a.Equals(t, err, nil)
 	a.EqualsArray(t, unpadded, []byte{0x01, 0x02, 0x03})
 
 	padded = bits.Pad([]byte{0x01, 0x02, 0x03}, 3)
-	unpadded = bits.Unpad(padded)
+	unpadded, err = bits.Unpad(padded, 3)
+	a.Equals(t, err, nil)
 	a.EqualsArray(t, unpadded, []byte{0x01, 0x02, 0x03})
 }
+
+func TestUnpadFailsWithInvalidPadding(t *testing.T) {
+	t.Parallel()
+
+	for _, message := range [][]byte{
+		{},
+		{0x00},
+		{0x01, 0x02, 0x03, 0x00},
+		{0x01, 0x02, 0x05},
+		{0x01, 0x02, 0x03, 0x02, 0x03, 0x03},
+		{0x01, 0x04, 0x04, 0x04},
+		{0x01, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09},
+		bytes.Repeat([]byte{0x20}, 32),
+	} {
+		_, err := bits.Unpad(message, 8)
+		a.EqualsError(t, err, ironerrors.ErrInvalidPadding)
+	}
+
+	_, err := bits.Unpad(bytes.Repeat([]byte{0x20}, 32), 16)
+	a.EqualsError(t, err, ironerrors.ErrInvalidPadding)
+
+	unpadded, err := bits.Unpad([]byte{0x03, 0x03, 0x03}, 8)
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, unpadded, []byte{})
+}
@@ -0,0 +1,49 @@
+package iron
+
+import (
+	"context"
+
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+// Seal raw bytes with a password according to the options in the seal options.
+//
+// The bytes are encrypted as they are instead of being marshalled with the codec in the seal options first, so a
+// JSON document sealed this way can still be unsealed by @hapi/iron and other payloads are not base64 encoded twice.
+//
+// Returns a string that can be unsealed with UnsealBytes using the same password and options.
+func SealBytes(message []byte, password pw.Raw, cfg SealConfig) (string, error) {
+	return SealBytesContext(context.Background(), message, password, cfg)
+}
+
+// Seal raw bytes with a password according to the options in the seal options, stopping with the context's error if
+// it is done before the key derivation, encryption or HMAC steps finish.
+func SealBytesContext(ctx context.Context, message []byte, password pw.Raw, cfg SealConfig) (string, error) {
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return "", err
+	}
+
+	return seal(ctx, str.FromBuffer(message), "", pass, cfg)
+}
+
+// Unseal a sealed value into the raw bytes that were sealed, using the password and seal options.
+//
+// The bytes are returned as they were decrypted, without unmarshalling them with the codec in the seal options.
+func UnsealBytes(sealed string, password pw.UnsealRaw, cfg SealConfig) ([]byte, error) {
+	return UnsealBytesContext(context.Background(), sealed, password, cfg)
+}
+
+// Unseal a sealed value into the raw bytes that were sealed, stopping with the context's error if it is done before
+// the key derivation, HMAC or decryption steps finish.
+func UnsealBytesContext(ctx context.Context, sealed string, password pw.UnsealRaw, cfg SealConfig) ([]byte, error) {
+	return unsealPayload(ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		return pw.NormaliseUnseal(password, passwordId)
+	}, cfg, "", decodeBytes)
+}
+
+// return the decrypted payload as it is
+func decodeBytes(payload string) ([]byte, error) {
+	return str.ToBuffer(payload), nil
+}
@@ -0,0 +1,117 @@
+package iron_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestBytesWorks(t *testing.T) {
+	t.Parallel()
+
+	message := make([]byte, 256)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+
+	sealed, err := iron.SealBytes(message, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	unsealed, err := iron.UnsealBytes(sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, unsealed, message)
+
+	// marshalling the bytes to JSON base64 encodes them before they are encrypted
+	sealedObject, err := iron.Seal(message, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, len(sealed) < len(sealedObject), true)
+}
+
+func TestBytesWorksWithAead(t *testing.T) {
+	t.Parallel()
+
+	message := []byte{0x00, 0xff, 0x10, 0x80}
+	encryption := iron.DefaultEncryption
+	encryption.Algorithm = key.AES256GCM
+
+	cfg := iron.SealConfig{
+		Encryption: encryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+
+	sealed, err := iron.SealBytes(message, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	unsealed, err := iron.UnsealBytes(sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(unsealed, message), true)
+}
+
+func TestBytesJsonDocumentCanBeUnsealedAsObject(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+
+	sealed, err := iron.SealBytes([]byte(`{"name":"iron"}`), pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	obj, err := iron.Unseal[map[string]string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj["name"], "iron")
+}
+
+func TestBytesWorksWithSealFromNode(t *testing.T) {
+	t.Parallel()
+
+	unsealed, err := iron.UnsealBytes(SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, string(unsealed), `"`+DecryptedMessage+`"`)
+}
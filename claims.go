@@ -0,0 +1,147 @@
+package iron
+
+import (
+	"time"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/james-elicx/go-utils/utils"
+)
+
+// Payload with registered claims about the message, validated when unsealing against the claims expectation in the
+// seal config.
+//
+// Times are in milliseconds since the Unix epoch, like the expiration of a seal. Zero values are not included.
+type Claims[T any] struct {
+	// Issuer of the claims.
+	Issuer string `json:"iss,omitempty"`
+	// Audiences the claims are intended for.
+	Audience []string `json:"aud,omitempty"`
+	// Subject of the claims.
+	Subject string `json:"sub,omitempty"`
+	// Time before which the claims must not be accepted.
+	NotBefore int64 `json:"nbf,omitempty"`
+	// Time the claims were issued. Set to the current time when sealing if it is zero.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// Unique identifier for the claims.
+	ID string `json:"jti,omitempty"`
+	// The message.
+	Data T `json:"data"`
+}
+
+// Expectation for the registered claims of a Claims payload when unsealing.
+type ClaimsExpectation struct {
+	// Issuer that must have issued the claims. Empty to accept any issuer.
+	Issuer string
+	// Audiences that are accepted. The claims must be intended for at least one of them. Empty to accept any audience.
+	Audiences []string
+	// Maximum time since the claims were issued. Zero for no maximum age.
+	MaxAge time.Duration
+}
+
+// Payloads with registered claims, that are stamped and validated without knowing the type of their message.
+type registeredClaims interface {
+	issued(now int64) any
+	validate(cfg SealConfig) error
+}
+
+// Payloads that may be a nil pointer to Claims, which have no claims to stamp or validate.
+type nillableClaims interface {
+	isNil() bool
+}
+
+func (c *Claims[T]) isNil() bool {
+	return c == nil
+}
+
+func (c Claims[T]) issued(now int64) any {
+	if c.IssuedAt == 0 {
+		c.IssuedAt = now
+	}
+
+	return c
+}
+
+func (c Claims[T]) validate(cfg SealConfig) error {
+	expected := ClaimsExpectation{}
+	if cfg.Claims != nil {
+		expected = *cfg.Claims
+	}
+
+	if expected.Issuer != "" && c.Issuer != expected.Issuer {
+		return invalidClaim("iss", ironerrors.ErrInvalidIssuer)
+	}
+	if len(expected.Audiences) > 0 && !hasAudience(c.Audience, expected.Audiences) {
+		return invalidClaim("aud", ironerrors.ErrInvalidAudience)
+	}
+
+	now := cfg.now()
+	skew := cfg.timestampSkew()
+
+	if c.NotBefore != 0 && now+skew < c.NotBefore {
+		return invalidClaim("nbf", ironerrors.ErrClaimsNotYetValid)
+	}
+	if c.IssuedAt != 0 && now+skew < c.IssuedAt {
+		return invalidClaim("iat", ironerrors.ErrClaimsNotYetValid)
+	}
+
+	if expected.MaxAge > 0 {
+		if c.IssuedAt == 0 {
+			return invalidClaim("iat", ironerrors.ErrMissingClaim)
+		}
+		if c.IssuedAt+expected.MaxAge.Milliseconds() <= now-skew {
+			return invalidClaim("iat", ironerrors.ErrClaimsTooOld)
+		}
+	}
+
+	return nil
+}
+
+// Set the issued at time of a message with registered claims.
+func issueClaims(message any, cfg SealConfig) any {
+	if claims, ok := message.(nillableClaims); ok && claims.isNil() {
+		return message
+	}
+	if claims, ok := message.(registeredClaims); ok {
+		return claims.issued(cfg.now())
+	}
+
+	return message
+}
+
+// Validate the registered claims of an unsealed object against the claims expectation in the seal config.
+func validateClaims(obj any, cfg SealConfig) error {
+	// a null payload has none of the claims the caller expects
+	if claims, ok := obj.(nillableClaims); ok && claims.isNil() {
+		return invalidClaim("", ironerrors.ErrMissingClaim)
+	}
+	if claims, ok := obj.(registeredClaims); ok {
+		return claims.validate(cfg)
+	}
+
+	return nil
+}
+
+// Allowed clock skew in milliseconds, in the same way as when checking the expiration of a seal.
+func (cfg SealConfig) timestampSkew() int64 {
+	return int64(utils.Ternary(cfg.TimestampSkewSec == 0, 60, utils.Ternary(cfg.TimestampSkewSec == -1, 0, cfg.TimestampSkewSec))) * 1000
+}
+
+func hasAudience(audience []string, allowed []string) bool {
+	for _, aud := range audience {
+		for _, a := range allowed {
+			if aud == a {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// create an error for a claim that is not valid
+func invalidClaim(claim string, err error) error {
+	return &ironerrors.ClaimError{
+		Claim: claim,
+		Err:   err,
+	}
+}
@@ -0,0 +1,297 @@
+package iron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func assertClaimError(t *testing.T, err error, claim string, sentinel error) {
+	a.Equals(t, errors.Is(err, sentinel), true)
+
+	var claimErr *ironerrors.ClaimError
+	a.Equals(t, errors.As(err, &claimErr), true)
+	a.Equals(t, claimErr.Claim, claim)
+}
+
+func TestClaimsWork(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+		Claims: &iron.ClaimsExpectation{
+			Issuer:    "auth",
+			Audiences: []string{"billing", "reports"},
+			MaxAge:    time.Hour,
+		},
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{
+		Issuer:   "auth",
+		Audience: []string{"reports"},
+		Subject:  "user-1",
+		ID:       "abc",
+		Data:     DecryptedMessage,
+	}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Minute)
+	claims, err := iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, claims.Issuer, "auth")
+	a.Equals(t, claims.Audience[0], "reports")
+	a.Equals(t, claims.Subject, "user-1")
+	a.Equals(t, claims.ID, "abc")
+	a.Equals(t, claims.IssuedAt, ttlNow.UnixMilli())
+	a.Equals(t, claims.Data, DecryptedMessage)
+}
+
+func TestClaimsKeepIssuedAt(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            clocktest.NewFake(ttlNow),
+	}
+
+	issuedAt := ttlNow.Add(-time.Minute).UnixMilli()
+	sealed, err := iron.Seal(iron.Claims[string]{IssuedAt: issuedAt}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	claims, err := iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, claims.IssuedAt, issuedAt)
+}
+
+func TestClaimsFailWithUnexpectedIssuer(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            clocktest.NewFake(ttlNow),
+		Claims:           &iron.ClaimsExpectation{Issuer: "auth"},
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{Issuer: "other"}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "iss", ironerrors.ErrInvalidIssuer)
+	a.Equals(t, err.Error(), "claims issuer is not the expected issuer (claim: iss)")
+
+	sealed, err = iron.Seal(iron.Claims[string]{}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "iss", ironerrors.ErrInvalidIssuer)
+}
+
+func TestClaimsFailWithUnexpectedAudience(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            clocktest.NewFake(ttlNow),
+		Claims:           &iron.ClaimsExpectation{Audiences: []string{"billing"}},
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{Audience: []string{"reports"}}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "aud", ironerrors.ErrInvalidAudience)
+
+	sealed, err = iron.Seal(iron.Claims[string]{}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "aud", ironerrors.ErrInvalidAudience)
+}
+
+func TestClaimsFailBeforeNotBefore(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{NotBefore: ttlNow.Add(time.Minute).UnixMilli()}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "nbf", ironerrors.ErrClaimsNotYetValid)
+
+	// the default skew accepts claims up to a minute early
+	cfg.TimestampSkewSec = 0
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	cfg.TimestampSkewSec = -1
+	fake.Advance(time.Minute)
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+}
+
+func TestClaimsFailWhenIssuedInTheFuture(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            clocktest.NewFake(ttlNow),
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{IssuedAt: ttlNow.Add(time.Second).UnixMilli()}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "iat", ironerrors.ErrClaimsNotYetValid)
+}
+
+func TestClaimsFailPastMaxAge(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+		Claims:           &iron.ClaimsExpectation{MaxAge: time.Minute},
+	}
+
+	sealed, err := iron.Seal(iron.Claims[string]{}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Minute - time.Millisecond)
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Millisecond)
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "iat", ironerrors.ErrClaimsTooOld)
+}
+
+func TestClaimsFailWithoutIssuedAtForMaxAge(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            clocktest.NewFake(ttlNow),
+		Claims:           &iron.ClaimsExpectation{MaxAge: time.Minute},
+	}
+
+	// a payload that was not sealed as claims does not have an issued at time
+	sealed, err := iron.Seal(map[string]string{"data": DecryptedMessage}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "iat", ironerrors.ErrMissingClaim)
+}
+
+func TestClaimsExpectationIgnoresOtherPayloads(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		Claims:     &iron.ClaimsExpectation{Issuer: "auth"},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	obj, err := iron.Unseal[string](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestSealerValidatesClaims(t *testing.T) {
+	t.Parallel()
+
+	sealer, err := iron.NewSealer[iron.Claims[string]](SealPassword, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		Clock:      clocktest.NewFake(ttlNow),
+		Claims:     &iron.ClaimsExpectation{Audiences: []string{"billing"}},
+	})
+	a.Equals(t, err, nil)
+
+	sealed, err := sealer.Seal(iron.Claims[string]{Audience: []string{"billing"}, Data: DecryptedMessage})
+	a.Equals(t, err, nil)
+
+	claims, err := sealer.Unseal(sealed)
+	a.Equals(t, err, nil)
+	a.Equals(t, claims.IssuedAt, ttlNow.UnixMilli())
+
+	sealed, err = sealer.Seal(iron.Claims[string]{Audience: []string{"reports"}})
+	a.Equals(t, err, nil)
+
+	_, err = sealer.Unseal(sealed)
+	assertClaimError(t, err, "aud", ironerrors.ErrInvalidAudience)
+}
+
+func TestSealingNilClaimsDoesNotPanic(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	sealed, err := iron.Seal[*iron.Claims[string]](nil, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[*iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "", ironerrors.ErrMissingClaim)
+}
+
+func TestUnsealingNullClaimsFails(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		Clock:      clocktest.NewFake(ttlNow),
+	}
+
+	sealed, err := iron.Seal[*string](nil, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	claims, err := iron.Unseal[*iron.Claims[string]](sealed, UnsealPassword, cfg)
+	assertClaimError(t, err, "", ironerrors.ErrMissingClaim)
+	a.Equals(t, claims == nil, true)
+	a.Equals(t, err.Error(), ironerrors.ErrMissingClaim.Error())
+
+	// a pointer to claims is validated like a value
+	sealed, err = iron.Seal(&iron.Claims[string]{Data: DecryptedMessage}, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	claims, err = iron.Unseal[*iron.Claims[string]](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, claims.IssuedAt, ttlNow.UnixMilli())
+	a.Equals(t, claims.Data, DecryptedMessage)
+}
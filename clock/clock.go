@@ -0,0 +1,29 @@
+package clock
+
+import "time"
+
+// A source of the current time.
+type Clock interface {
+	// Retrieve the current time.
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+var (
+	// Clock that reads the system time.
+	System Clock = systemClock{}
+)
+
+// Retrieve the clock to use, falling back to the system clock when c is nil.
+func Or(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+
+	return c
+}
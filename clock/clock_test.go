@@ -0,0 +1,28 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/clock"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestOrFallsBackToSystemClock(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, clock.Or(nil), clock.System)
+
+	before := time.Now()
+	now := clock.Or(nil).Now()
+	a.Equals(t, now.Before(before), false)
+}
+
+func TestOrUsesSuppliedClock(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1000))
+
+	a.Equals(t, clock.Or(fake).Now(), time.UnixMilli(1000))
+}
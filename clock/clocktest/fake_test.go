@@ -0,0 +1,22 @@
+package clocktest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestFakeOnlyMovesWhenTold(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1000))
+	a.Equals(t, fake.Now(), time.UnixMilli(1000))
+
+	fake.Advance(time.Second)
+	a.Equals(t, fake.Now(), time.UnixMilli(2000))
+
+	fake.Set(time.UnixMilli(500))
+	a.Equals(t, fake.Now(), time.UnixMilli(500))
+}
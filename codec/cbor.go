@@ -0,0 +1,22 @@
+package codec
+
+import "github.com/fxamacker/cbor/v2"
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string {
+	return "cbor"
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
+
+var (
+	// CBOR codec, as defined in RFC 8949.
+	CBOR Codec = cborCodec{}
+)
@@ -0,0 +1,48 @@
+package codec
+
+import (
+	"strings"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+)
+
+// Marshals payloads to bytes before they are sealed, and unmarshals them after they are unsealed.
+//
+// The codec's name is recorded in seals that do not use JSON, so Unseal can reject payloads sealed with a different
+// codec.
+//
+// Codecs are used by setting SealConfig.Codec, when sealing and unsealing. Protobuf messages can be sealed with a codec
+// that wraps proto.Marshal and proto.Unmarshal, type asserting values to proto.Message.
+type Codec interface {
+	// Name recorded in the seal. Letters, digits, '-', '.' and '_' only.
+	Name() string
+	// Marshal a value to bytes.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal bytes into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// Check a codec name can be recorded in a seal.
+func ValidateName(name string) error {
+	if name == "" {
+		return ironerrors.ErrInvalidCodec
+	}
+
+	invalid := strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_')
+	})
+	if invalid != -1 {
+		return ironerrors.ErrInvalidCodec
+	}
+
+	return nil
+}
+
+// Retrieve the codec to use, falling back to JSON when c is nil.
+func Or(c Codec) Codec {
+	if c == nil {
+		return JSON
+	}
+
+	return c
+}
@@ -0,0 +1,45 @@
+package codec_test
+
+import (
+	"testing"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+type payload struct {
+	Name  string `json:"name" cbor:"name" msgpack:"name"`
+	Count int    `json:"count" cbor:"count" msgpack:"count"`
+}
+
+func TestBuiltInCodecsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []codec.Codec{codec.JSON, codec.CBOR, codec.MessagePack} {
+		b, err := c.Marshal(payload{Name: "iron", Count: 3})
+		a.Equals(t, err, nil)
+
+		var obj payload
+		err = c.Unmarshal(b, &obj)
+		a.Equals(t, err, nil)
+		a.Equals(t, obj, payload{Name: "iron", Count: 3})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, codec.ValidateName("proto.v1_x-y"), nil)
+
+	for _, name := range []string{"", "a*b", "a~b", "a=b", "a b"} {
+		a.EqualsError(t, codec.ValidateName(name), ironerrors.ErrInvalidCodec)
+	}
+}
+
+func TestOrFallsBackToJSON(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, codec.Or(nil), codec.JSON)
+	a.Equals(t, codec.Or(codec.CBOR), codec.CBOR)
+}
@@ -0,0 +1,22 @@
+package codec
+
+import "encoding/json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+var (
+	// JSON codec, using encoding/json. Seals with JSON payloads can be unsealed by @hapi/iron.
+	JSON Codec = jsonCodec{}
+)
@@ -0,0 +1,168 @@
+package iron_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+type codecMessage struct {
+	Name  string   `json:"name" cbor:"name" msgpack:"name"`
+	Roles []string `json:"roles" cbor:"roles" msgpack:"roles"`
+}
+
+type namedCodec struct {
+	codec.Codec
+	name string
+}
+
+func (c namedCodec) Name() string {
+	return c.name
+}
+
+func TestCodecsWork(t *testing.T) {
+	t.Parallel()
+
+	message := codecMessage{Name: "iron", Roles: []string{"admin", "user"}}
+	aead := iron.DefaultEncryption
+	aead.Algorithm = key.AES256GCM
+
+	for _, c := range []codec.Codec{codec.CBOR, codec.MessagePack} {
+		for _, encryption := range []iron.SealConfigOptions{iron.DefaultEncryption, aead} {
+			cfg := iron.SealConfig{
+				Encryption: encryption,
+				Integrity:  iron.DefaultIntegrity,
+				Codec:      c,
+			}
+
+			sealed, err := iron.Seal(message, pw.Raw{
+				Password: pw.Password{
+					String: DecryptedPassword,
+				},
+			}, cfg)
+			a.Equals(t, err, nil)
+			a.Equals(t, strings.Contains(strings.Split(sealed, "*")[0], "~codec="+c.Name()), true)
+
+			obj, err := iron.Unseal[codecMessage](sealed, pw.UnsealRaw{
+				Password: pw.Password{
+					String: DecryptedPassword,
+				},
+			}, cfg)
+			a.Equals(t, err, nil)
+			a.Equals(t, obj.Name, message.Name)
+			a.EqualsArray(t, obj.Roles, message.Roles)
+		}
+	}
+}
+
+func TestJsonCodecIsNotRecorded(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      codec.JSON,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2")
+}
+
+func TestCodecFailsWithUnexpectedCodec(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      codec.CBOR,
+	})
+	a.Equals(t, err, nil)
+
+	for _, c := range []codec.Codec{nil, codec.MessagePack} {
+		_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+			Password: pw.Password{
+				String: DecryptedPassword,
+			},
+		}, iron.SealConfig{
+			Encryption: iron.DefaultEncryption,
+			Integrity:  iron.DefaultIntegrity,
+			Codec:      c,
+		})
+		a.Equals(t, errors.Is(err, ironerrors.ErrUnexpectedCodec), true)
+	}
+
+	_, err = iron.Unseal[string](SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		Codec:      codec.CBOR,
+	})
+	a.Equals(t, errors.Is(err, ironerrors.ErrUnexpectedCodec), true)
+}
+
+func TestCodecFailsWithInvalidName(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      namedCodec{Codec: codec.JSON, name: "json*"},
+	}
+
+	_, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.EqualsError(t, err, ironerrors.ErrInvalidCodec)
+
+	_, err = iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{}, cfg)
+	a.EqualsError(t, err, ironerrors.ErrInvalidCodec)
+}
+
+func TestCodecWorksWithCustomCodec(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      namedCodec{Codec: codec.JSON, name: "custom"},
+	}
+
+	sealer, err := iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{}, cfg)
+	a.Equals(t, err, nil)
+
+	sealed, err := sealer.Seal(DecryptedMessage)
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2~codec=custom")
+
+	obj, err := sealer.Unseal(sealed)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
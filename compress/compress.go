@@ -0,0 +1,110 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+)
+
+// Compresses payloads before they are encrypted, and decompresses them after they are decrypted.
+//
+// The compressor's name is recorded in seals with compressed payloads, so Unseal knows to decompress them.
+type Compressor interface {
+	// Name recorded in the seal. Letters, digits, '-', '.' and '_' only.
+	Name() string
+	// Compress the data.
+	Compress(data []byte) ([]byte, error)
+	// Create a reader that decompresses data from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+const (
+	// Default maximum size in bytes of a decompressed payload.
+	DefaultMaxSize = 1 << 20
+)
+
+var (
+	registry   = map[string]Compressor{}
+	registryMu sync.RWMutex
+)
+
+func init() {
+	for _, c := range []Compressor{Deflate, Zstd} {
+		registry[c.Name()] = c
+	}
+}
+
+// Register a compressor so seals compressed with it can be decompressed.
+//
+// Returns an error if the name is invalid or a compressor with the same name is already registered.
+func Register(c Compressor) error {
+	if err := ValidateName(c.Name()); err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[c.Name()]; ok {
+		return ironerrors.ErrCompressorAlreadyRegistered
+	}
+
+	registry[c.Name()] = c
+
+	return nil
+}
+
+// Look up a registered compressor by name.
+func Lookup(name string) (Compressor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	c, ok := registry[name]
+
+	return c, ok
+}
+
+// Check a compressor name can be recorded in a seal.
+func ValidateName(name string) error {
+	if name == "" {
+		return ironerrors.ErrInvalidCompressor
+	}
+
+	invalid := strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_')
+	})
+	if invalid != -1 {
+		return ironerrors.ErrInvalidCompressor
+	}
+
+	return nil
+}
+
+// Decompress the data with the compressor, failing once the output exceeds maxSize bytes.
+//
+// A maxSize of 0 uses DefaultMaxSize.
+func Decompress(c Compressor, data []byte, maxSize int) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	r, err := c.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ironerrors.ErrDecompressing
+	}
+	defer r.Close()
+
+	// read one byte past the limit, so output of exactly maxSize bytes is allowed
+	out, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, ironerrors.ErrDecompressing
+	}
+	if len(out) > maxSize {
+		return nil, ironerrors.ErrDecompressedTooLarge
+	}
+
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package compress_test
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+type identity struct {
+	name string
+}
+
+func (c identity) Name() string                               { return c.name }
+func (identity) Compress(data []byte) ([]byte, error)         { return data, nil }
+func (identity) NewReader(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+
+var (
+	payload = []byte(strings.Repeat(`{"name":"iron","roles":["admin","user"]}`, 100))
+
+	// the registry is global, so each run of TestRegister registers a new name
+	registered atomic.Int64
+)
+
+func TestBuiltInCompressorsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []compress.Compressor{compress.Deflate, compress.Zstd} {
+		compressed, err := c.Compress(payload)
+		a.Equals(t, err, nil)
+		a.Equals(t, len(compressed) < len(payload), true)
+
+		decompressed, err := compress.Decompress(c, compressed, 0)
+		a.Equals(t, err, nil)
+		a.Equals(t, bytes.Equal(decompressed, payload), true)
+	}
+}
+
+func TestDecompressLimitsOutputSize(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []compress.Compressor{compress.Deflate, compress.Zstd} {
+		compressed, err := c.Compress(payload)
+		a.Equals(t, err, nil)
+
+		_, err = compress.Decompress(c, compressed, len(payload))
+		a.Equals(t, err, nil)
+
+		_, err = compress.Decompress(c, compressed, len(payload)-1)
+		a.EqualsError(t, err, ironerrors.ErrDecompressedTooLarge)
+	}
+
+	bomb, err := compress.Deflate.Compress(make([]byte, compress.DefaultMaxSize+1))
+	a.Equals(t, err, nil)
+
+	_, err = compress.Decompress(compress.Deflate, bomb, 0)
+	a.EqualsError(t, err, ironerrors.ErrDecompressedTooLarge)
+}
+
+func TestDecompressFailsWithInvalidData(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []compress.Compressor{compress.Deflate, compress.Zstd} {
+		_, err := compress.Decompress(c, []byte("not compressed data"), 0)
+		a.EqualsError(t, err, ironerrors.ErrDecompressing)
+	}
+}
+
+func TestBuiltInCompressorsAreRegistered(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"deflate", "zstd"} {
+		c, ok := compress.Lookup(name)
+		a.Equals(t, ok, true)
+		a.Equals(t, c.Name(), name)
+	}
+
+	_, ok := compress.Lookup("unknown")
+	a.Equals(t, ok, false)
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	c := identity{name: "identity-" + strconv.FormatInt(registered.Add(1), 10)}
+
+	err := compress.Register(c)
+	a.Equals(t, err, nil)
+
+	found, ok := compress.Lookup(c.name)
+	a.Equals(t, ok, true)
+	a.Equals(t, found, compress.Compressor(c))
+
+	err = compress.Register(c)
+	a.EqualsError(t, err, ironerrors.ErrCompressorAlreadyRegistered)
+}
+
+func TestValidateName(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, compress.ValidateName("br.v1_x-y"), nil)
+
+	for _, name := range []string{"", "a*b", "a~b", "a=b"} {
+		a.EqualsError(t, compress.ValidateName(name), ironerrors.ErrInvalidCompressor)
+	}
+}
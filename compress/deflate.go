@@ -0,0 +1,41 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+)
+
+type deflateCompressor struct{}
+
+func (deflateCompressor) Name() string {
+	return "deflate"
+}
+
+func (deflateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, ironerrors.ErrCompressing
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, ironerrors.ErrCompressing
+	}
+	if err = w.Close(); err != nil {
+		return nil, ironerrors.ErrCompressing
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (deflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+var (
+	// Raw DEFLATE compressor, as defined in RFC 1951.
+	Deflate Compressor = deflateCompressor{}
+)
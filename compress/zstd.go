@@ -0,0 +1,47 @@
+package compress
+
+import (
+	"io"
+	"sync"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/klauspost/compress/zstd"
+)
+
+type zstdCompressor struct{}
+
+var (
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+	zstdEncoderOnce sync.Once
+)
+
+func (zstdCompressor) Name() string {
+	return "zstd"
+}
+
+func (zstdCompressor) Compress(data []byte) ([]byte, error) {
+	// an encoder is safe for concurrent use with EncodeAll, so one is shared
+	zstdEncoderOnce.Do(func() {
+		zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	})
+	if zstdEncoderErr != nil {
+		return nil, ironerrors.ErrCompressing
+	}
+
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (zstdCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return d.IOReadCloser(), nil
+}
+
+var (
+	// Zstandard compressor, as defined in RFC 8878.
+	Zstd Compressor = zstdCompressor{}
+)
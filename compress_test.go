@@ -0,0 +1,148 @@
+package iron_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+type unregisteredCompressor struct {
+	compress.Compressor
+}
+
+func (unregisteredCompressor) Name() string {
+	return "unregistered"
+}
+
+var (
+	compressibleMessage = strings.Repeat("Hello World! ", 200)
+)
+
+func TestCompressionWorks(t *testing.T) {
+	t.Parallel()
+
+	aead := iron.DefaultEncryption
+	aead.Algorithm = key.XCHACHA20POLY1305
+
+	uncompressed, err := iron.Seal(compressibleMessage, SealPassword, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+
+	for _, c := range []compress.Compressor{compress.Deflate, compress.Zstd} {
+		for _, encryption := range []iron.SealConfigOptions{iron.DefaultEncryption, aead} {
+			sealed, err := iron.Seal(compressibleMessage, SealPassword, iron.SealConfig{
+				Encryption: encryption,
+				Integrity:  iron.DefaultIntegrity,
+				Compressor: c,
+			})
+			a.Equals(t, err, nil)
+
+			a.Equals(t, strings.HasSuffix(strings.Split(sealed, "*")[0], "~zip="+c.Name()), true)
+			a.Equals(t, len(sealed) < len(uncompressed)/4, true)
+
+			// the seal records the compressor, so it does not need to be configured to unseal
+			obj, err := iron.Unseal[string](sealed, UnsealPassword, iron.SealConfig{
+				Encryption: encryption,
+				Integrity:  iron.DefaultIntegrity,
+			})
+			a.Equals(t, err, nil)
+			a.Equals(t, obj, compressibleMessage)
+		}
+	}
+}
+
+func TestCompressionWorksWithCodec(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      codec.MessagePack,
+		Compressor: compress.Zstd,
+	}
+
+	sealed, err := iron.Seal(compressibleMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2~codec=msgpack~zip=zstd")
+
+	obj, err := iron.Unseal[string](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, compressibleMessage)
+}
+
+func TestCompressionSkipsSmallPayloads(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:        iron.DefaultEncryption,
+		Integrity:         iron.DefaultIntegrity,
+		Compressor:        compress.Deflate,
+		CompressThreshold: 1024,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2")
+
+	sealed, err = iron.Seal(compressibleMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2~zip=deflate")
+
+	// payloads that do not get smaller are not compressed
+	sealed, err = iron.Seal(DecryptedMessage, SealPassword, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Compressor: compress.Deflate,
+	})
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2")
+}
+
+func TestCompressionFailsWhenDecompressedTooLarge(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption:          iron.DefaultEncryption,
+		Integrity:           iron.DefaultIntegrity,
+		Compressor:          compress.Deflate,
+		MaxDecompressedSize: 1024,
+	}
+
+	sealed, err := iron.Seal(compressibleMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[string](sealed, UnsealPassword, cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecompressedTooLarge)
+}
+
+func TestCompressionFailsWithUnsupportedCompressor(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Compressor: unregisteredCompressor{compress.Deflate},
+	}
+
+	sealed, err := iron.Seal(compressibleMessage, SealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[0], "Fe26.2~zip=unregistered")
+
+	obj, err := iron.Unseal[string](sealed, UnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, compressibleMessage)
+
+	_, err = iron.Unseal[string](sealed, UnsealPassword, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.EqualsError(t, err, ironerrors.ErrUnsupportedCompression)
+}
@@ -0,0 +1,112 @@
+package iron_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestSealContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := iron.SealContext(ctx, DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+
+	a.EqualsError(t, err, context.Canceled)
+}
+
+func TestSealContextStopsAfterSlowKeyDerivation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	encryption := iron.DefaultEncryption
+	encryption.KDF = key.Scrypt
+	encryption.Scrypt = key.ScryptParams{N: 1 << 17}
+
+	_, err := iron.SealContext(ctx, DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: encryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+
+	a.EqualsError(t, err, context.DeadlineExceeded)
+}
+
+func TestUnsealContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := iron.UnsealContext[string](ctx, SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+
+	a.EqualsError(t, err, context.Canceled)
+}
+
+func TestUnsealContextWorks(t *testing.T) {
+	t.Parallel()
+
+	obj, err := iron.UnsealContext[string](context.Background(), SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestSealerContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	sealer, err := iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+
+	sealed, err := sealer.SealContext(context.Background(), DecryptedMessage)
+	a.Equals(t, err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = sealer.SealContext(ctx, DecryptedMessage)
+	a.EqualsError(t, err, context.Canceled)
+
+	_, err = sealer.UnsealContext(ctx, sealed)
+	a.EqualsError(t, err, context.Canceled)
+}
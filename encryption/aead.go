@@ -0,0 +1,62 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Create the AEAD cipher for the generated key, checking the IV is a valid nonce for it.
+func NewAEAD(k key.GeneratedKey) (cipher.AEAD, error) {
+	var aead cipher.AEAD
+	var err error
+
+	switch k.Algorithm {
+	case key.AES256GCM:
+		block, blockErr := aes.NewCipher(k.Key)
+		if blockErr != nil {
+			return nil, ironerrors.ErrCreatingCipher
+		}
+		aead, err = cipher.NewGCM(block)
+	case key.CHACHA20POLY1305:
+		aead, err = chacha20poly1305.New(k.Key)
+	case key.XCHACHA20POLY1305:
+		aead, err = chacha20poly1305.NewX(k.Key)
+	default:
+		return nil, ironerrors.ErrInvalidEncryptionAlgorithm
+	}
+
+	if err != nil {
+		return nil, ironerrors.ErrCreatingCipher
+	}
+	if len(k.IV) != aead.NonceSize() {
+		return nil, invalidIV()
+	}
+
+	return aead, nil
+}
+
+// Create the AES block cipher for the generated key, checking the IV is the size of a block.
+func newBlockCipher(k key.GeneratedKey) (cipher.Block, error) {
+	block, err := aes.NewCipher(k.Key)
+	if err != nil {
+		return nil, ironerrors.ErrCreatingCipher
+	}
+	if len(k.IV) != block.BlockSize() {
+		return nil, invalidIV()
+	}
+
+	return block, nil
+}
+
+// create an error for an IV that is the wrong size for the algorithm
+func invalidIV() error {
+	return &ironerrors.SealError{
+		Reason: ironerrors.ReasonInvalidField,
+		Field:  ironerrors.FieldIV,
+		Err:    ironerrors.ErrInvalidIV,
+	}
+}
@@ -1,6 +1,7 @@
 package encryption
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 
@@ -12,7 +13,22 @@ import (
 
 // Encrypt the given string according to the encryption config.
 func Decrypt(cfg key.Config, cipherText []byte) (string, error) {
-	k, err := key.Generate(cfg)
+	return DecryptWithAdditionalData(cfg, cipherText, nil)
+}
+
+// Decrypt the given cipher text according to the encryption config.
+//
+// The additional data must match the data supplied when encrypting with an AEAD algorithm.
+func DecryptWithAdditionalData(cfg key.Config, cipherText []byte, additionalData []byte) (string, error) {
+	return DecryptContext(context.Background(), cfg, cipherText, additionalData)
+}
+
+// Decrypt the given cipher text according to the encryption config, stopping with the context's error if it is done
+// before the cipher text is decrypted.
+//
+// The additional data must match the data supplied when encrypting with an AEAD algorithm.
+func DecryptContext(ctx context.Context, cfg key.Config, cipherText []byte, additionalData []byte) (string, error) {
+	k, err := key.GenerateContext(ctx, cfg)
 	if err != nil {
 		return "", err
 	}
@@ -22,24 +38,58 @@ func Decrypt(cfg key.Config, cipherText []byte) (string, error) {
 		return aes256cbcDecrypt(k, cipherText)
 	case key.AES128CTR:
 		return aes128ctrDecrypt(k, cipherText)
+	case key.AES128CFB:
+		return aes128cfbDecrypt(k, cipherText)
+	case key.AES256GCM, key.CHACHA20POLY1305, key.XCHACHA20POLY1305:
+		return aeadDecrypt(k, cipherText, additionalData)
 	default:
 		return "", ironerrors.ErrInvalidEncryptionAlgorithm
 	}
 }
 
 func aes256cbcDecrypt(k key.GeneratedKey, cipherText []byte) (string, error) {
-	block, _ := aes.NewCipher(k.Key)
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return "", err
+	}
+
+	// padding always adds at least one byte, so the cipher text is never empty
+	if len(cipherText) == 0 || len(cipherText)%aes.BlockSize != 0 {
+		return "", invalidPadding()
+	}
 
 	plainText := str.MakeBuffer(len(cipherText))
 
 	mode := cipher.NewCBCDecrypter(block, k.IV)
 	mode.CryptBlocks(plainText, cipherText)
 
-	return str.FromBuffer(bits.Unpad(plainText)), nil
+	unpadded, err := bits.Unpad(plainText, aes.BlockSize)
+	if err != nil {
+		return "", invalidPadding()
+	}
+
+	return str.FromBuffer(unpadded), nil
 }
 
 func aes128ctrDecrypt(k key.GeneratedKey, cipherText []byte) (string, error) {
-	block, _ := aes.NewCipher(k.Key)
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return "", err
+	}
+
+	plainText := str.MakeBuffer(len(cipherText))
+
+	mode := cipher.NewCTR(block, k.IV)
+	mode.XORKeyStream(plainText, cipherText)
+
+	return str.FromBuffer(plainText), nil
+}
+
+func aes128cfbDecrypt(k key.GeneratedKey, cipherText []byte) (string, error) {
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return "", err
+	}
 
 	plainText := str.MakeBuffer(len(cipherText))
 
@@ -48,3 +98,35 @@ func aes128ctrDecrypt(k key.GeneratedKey, cipherText []byte) (string, error) {
 
 	return str.FromBuffer(plainText), nil
 }
+
+func aeadDecrypt(k key.GeneratedKey, cipherText []byte, additionalData []byte) (string, error) {
+	aead, err := NewAEAD(k)
+	if err != nil {
+		return "", err
+	}
+
+	plainText, err := aead.Open(nil, k.IV, cipherText, additionalData)
+	if err != nil {
+		return "", decryptFailed()
+	}
+
+	return str.FromBuffer(plainText), nil
+}
+
+// create an error for a payload that could not be decrypted
+func decryptFailed() error {
+	return &ironerrors.SealError{
+		Reason: ironerrors.ReasonDecryptFailed,
+		Field:  ironerrors.FieldPayload,
+		Err:    ironerrors.ErrDecrypting,
+	}
+}
+
+// create an error for a decrypted payload without valid padding
+func invalidPadding() error {
+	return &ironerrors.SealError{
+		Reason: ironerrors.ReasonDecryptFailed,
+		Field:  ironerrors.FieldPayload,
+		Err:    ironerrors.ErrInvalidPadding,
+	}
+}
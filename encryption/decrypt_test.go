@@ -1,6 +1,8 @@
 package encryption_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/iron-auth/iron-crypto/encryption"
@@ -47,6 +49,183 @@ func TestAes128ctrDecrypt(t *testing.T) {
 	a.Equals(t, data, DecryptedMessage)
 }
 
+func TestAes128ctrDecryptLongMessage(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Decrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES128CTR,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes128ctrGeneratedKey.Salt,
+			IV:                Aes128ctrGeneratedKey.IV,
+		},
+	}, Aes128ctrEncryptedLongMessage)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, data, DecryptedLongMessage)
+}
+
+func TestAes128cfbDecrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Decrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES128CFB,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes128ctrGeneratedKey.Salt,
+			IV:                Aes128ctrGeneratedKey.IV,
+		},
+	}, Aes128cfbEncryptedLongMessage)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, data, DecryptedLongMessage)
+}
+
+func TestAes256gcmDecrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Decrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256gcmGeneratedKey.Salt,
+			IV:                Aes256gcmGeneratedKey.IV,
+		},
+	}, Aes256gcmEncryptedPassword)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, data, DecryptedMessage)
+}
+
+func TestAes256gcmDecryptFailsWithTamperedData(t *testing.T) {
+	t.Parallel()
+
+	cfg := key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256gcmGeneratedKey.Salt,
+			IV:                Aes256gcmGeneratedKey.IV,
+		},
+	}
+
+	tampered := append([]byte{}, Aes256gcmEncryptedPassword...)
+	tampered[0] ^= 0x01
+
+	_, err := encryption.Decrypt(cfg, tampered)
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+
+	_, err = encryption.DecryptWithAdditionalData(cfg, Aes256gcmEncryptedPassword, []byte("additional data"))
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
+func TestAes256gcmDecryptWithAdditionalData(t *testing.T) {
+	t.Parallel()
+
+	cfg := key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256gcmGeneratedKey.Salt,
+			IV:                Aes256gcmGeneratedKey.IV,
+		},
+	}
+
+	data, err := encryption.EncryptWithAdditionalData(cfg, DecryptedMessage, []byte("additional data"))
+	a.Equals(t, err, nil)
+
+	decrypted, err := encryption.DecryptWithAdditionalData(cfg, data.Encrypted, []byte("additional data"))
+	a.Equals(t, err, nil)
+	a.Equals(t, decrypted, DecryptedMessage)
+}
+
+func TestChacha20poly1305Decrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.DecryptWithAdditionalData(key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.CHACHA20POLY1305,
+			IV:        Chacha20poly1305Nonce,
+		},
+	}, Chacha20poly1305Encrypted, Chacha20AdditionalData)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, data, Chacha20Message)
+}
+
+func TestChacha20poly1305DecryptFailsWithTamperedData(t *testing.T) {
+	t.Parallel()
+
+	cfg := key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.CHACHA20POLY1305,
+			IV:        Chacha20poly1305Nonce,
+		},
+	}
+
+	tampered := append([]byte{}, Chacha20poly1305Encrypted...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	_, err := encryption.DecryptWithAdditionalData(cfg, tampered, Chacha20AdditionalData)
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+
+	_, err = encryption.DecryptWithAdditionalData(cfg, Chacha20poly1305Encrypted, nil)
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
+func TestXchacha20poly1305Decrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.DecryptWithAdditionalData(key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.XCHACHA20POLY1305,
+			IV:        Xchacha20poly1305Nonce,
+		},
+	}, Xchacha20poly1305Encrypted, Chacha20AdditionalData)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, data, Chacha20Message)
+}
+
+func TestXchacha20poly1305DecryptFailsWithTamperedData(t *testing.T) {
+	t.Parallel()
+
+	cfg := key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.XCHACHA20POLY1305,
+			IV:        Xchacha20poly1305Nonce,
+		},
+	}
+
+	tampered := append([]byte{}, Xchacha20poly1305Encrypted...)
+	tampered[len(tampered)-1] ^= 0x01
+
+	_, err := encryption.DecryptWithAdditionalData(cfg, tampered, Chacha20AdditionalData)
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+
+	_, err = encryption.DecryptWithAdditionalData(cfg, Xchacha20poly1305Encrypted, nil)
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
 func TestSha256DecryptReturnsError(t *testing.T) {
 	t.Parallel()
 
@@ -78,7 +257,7 @@ func TestDecryptFailForInvalidPassword(t *testing.T) {
 		},
 	}, Aes256cbcEncryptedPassword)
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestDecryptFailForInvalidAlgo(t *testing.T) {
@@ -98,3 +277,108 @@ func TestDecryptFailForInvalidAlgo(t *testing.T) {
 
 	a.Equals(t, err, ironerrors.ErrInvalidEncryptionAlgorithm)
 }
+
+func TestDecryptContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := encryption.DecryptContext(ctx, key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			IV:                Aes256cbcGeneratedKey.IV,
+		},
+	}, Aes256cbcEncryptedPassword, nil)
+	a.EqualsError(t, err, context.Canceled)
+}
+
+func TestAes256cbcDecryptFailsWithInvalidPadding(t *testing.T) {
+	t.Parallel()
+
+	// the message is padded with 4 bytes, so flipping the IV makes the final byte claim 32 bytes of padding
+	iv := append([]byte{}, Aes256cbcGeneratedKey.IV...)
+	iv[len(iv)-1] ^= 0x04 ^ 0x20
+
+	for _, tc := range []struct {
+		iv         []byte
+		cipherText []byte
+	}{
+		{Aes256cbcGeneratedKey.IV, []byte{}},
+		{Aes256cbcGeneratedKey.IV, Aes256cbcEncryptedPassword[:len(Aes256cbcEncryptedPassword)-1]},
+		{iv, Aes256cbcEncryptedPassword},
+	} {
+		_, err := encryption.Decrypt(key.Config{
+			Password: DecryptedPassword,
+			Options: key.OptionsConfig{
+				Algorithm:         key.AES256CBC,
+				Iterations:        2,
+				MinPasswordLength: 32,
+				SaltBits:          256,
+				Salt:              Aes256cbcGeneratedKey.Salt,
+				IV:                tc.iv,
+			},
+		}, tc.cipherText)
+
+		a.Equals(t, errors.Is(err, ironerrors.ErrInvalidPadding), true)
+	}
+}
+
+func TestDecryptFailsWithInvalidIV(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []key.Algorithm{key.AES256CBC, key.AES128CTR, key.AES128CFB} {
+		_, err := encryption.Decrypt(key.Config{
+			Password: DecryptedPassword,
+			Options: key.OptionsConfig{
+				Algorithm:         algorithm,
+				Iterations:        2,
+				MinPasswordLength: 32,
+				SaltBits:          256,
+				Salt:              Aes256cbcGeneratedKey.Salt,
+				IV:                Aes256cbcGeneratedKey.IV[:8],
+			},
+		}, Aes256cbcEncryptedPassword)
+
+		a.Equals(t, errors.Is(err, ironerrors.ErrInvalidIV), true)
+	}
+}
+
+var fuzzAlgorithms = []key.Algorithm{
+	key.AES256CBC,
+	key.AES128CTR,
+	key.AES128CFB,
+	key.AES256GCM,
+	key.CHACHA20POLY1305,
+	key.XCHACHA20POLY1305,
+}
+
+func FuzzDecrypt(f *testing.F) {
+	f.Add(uint8(0), Aes256cbcEncryptedPassword, Aes256cbcGeneratedKey.IV)
+	f.Add(uint8(0), []byte{}, Aes256cbcGeneratedKey.IV)
+	f.Add(uint8(0), Aes256cbcEncryptedPassword[:15], Aes256cbcGeneratedKey.IV)
+	f.Add(uint8(1), Aes128ctrEncryptedPassword, Aes128ctrGeneratedKey.IV)
+	f.Add(uint8(2), Aes128cfbEncryptedLongMessage, Aes128ctrGeneratedKey.IV[:4])
+	f.Add(uint8(3), Aes256gcmEncryptedPassword, Aes256gcmGeneratedKey.IV)
+	f.Add(uint8(4), []byte{0x00}, []byte{})
+
+	f.Fuzz(func(t *testing.T, algorithm uint8, cipherText []byte, iv []byte) {
+		// the key is derived for every input, so keep to the smallest iteration count
+		_, _ = encryption.Decrypt(key.Config{
+			Password: DecryptedPassword,
+			Options: key.OptionsConfig{
+				Algorithm:         fuzzAlgorithms[int(algorithm)%len(fuzzAlgorithms)],
+				Iterations:        1,
+				MinPasswordLength: 32,
+				SaltBits:          256,
+				Salt:              Aes256cbcGeneratedKey.Salt,
+				IV:                iv,
+			},
+		}, cipherText)
+	})
+}
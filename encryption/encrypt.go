@@ -1,6 +1,7 @@
 package encryption
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 
@@ -20,7 +21,22 @@ type EncryptedData struct {
 
 // Encrypt the given string according to the encryption config.
 func Encrypt(cfg key.Config, message string) (EncryptedData, error) {
-	k, err := key.Generate(cfg)
+	return EncryptWithAdditionalData(cfg, message, nil)
+}
+
+// Encrypt the given string according to the encryption config.
+//
+// The additional data is authenticated, but not encrypted, by AEAD algorithms and ignored by the others.
+func EncryptWithAdditionalData(cfg key.Config, message string, additionalData []byte) (EncryptedData, error) {
+	return EncryptContext(context.Background(), cfg, message, additionalData)
+}
+
+// Encrypt the given string according to the encryption config, stopping with the context's error if it is done
+// before the message is encrypted.
+//
+// The additional data is authenticated, but not encrypted, by AEAD algorithms and ignored by the others.
+func EncryptContext(ctx context.Context, cfg key.Config, message string, additionalData []byte) (EncryptedData, error) {
+	k, err := key.GenerateContext(ctx, cfg)
 	if err != nil {
 		return EncryptedData{}, err
 	}
@@ -30,13 +46,20 @@ func Encrypt(cfg key.Config, message string) (EncryptedData, error) {
 		return aes256cbcEncrypt(k, message)
 	case key.AES128CTR:
 		return aes128ctrEncrypt(k, message)
+	case key.AES128CFB:
+		return aes128cfbEncrypt(k, message)
+	case key.AES256GCM, key.CHACHA20POLY1305, key.XCHACHA20POLY1305:
+		return aeadEncrypt(k, message, additionalData)
 	default:
 		return EncryptedData{}, ironerrors.ErrInvalidEncryptionAlgorithm
 	}
 }
 
 func aes256cbcEncrypt(k key.GeneratedKey, message string) (EncryptedData, error) {
-	block, _ := aes.NewCipher(k.Key)
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return EncryptedData{}, err
+	}
 
 	plainText := bits.Pad(str.ToBuffer(message), aes.BlockSize)
 	cipherText := str.MakeBuffer(len(plainText))
@@ -51,7 +74,28 @@ func aes256cbcEncrypt(k key.GeneratedKey, message string) (EncryptedData, error)
 }
 
 func aes128ctrEncrypt(k key.GeneratedKey, message string) (EncryptedData, error) {
-	block, _ := aes.NewCipher(k.Key)
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return EncryptedData{}, err
+	}
+
+	plainText := str.ToBuffer(message)
+	cipherText := str.MakeBuffer(len(plainText))
+
+	mode := cipher.NewCTR(block, k.IV)
+	mode.XORKeyStream(cipherText, plainText)
+
+	return EncryptedData{
+		Encrypted: cipherText,
+		Key:       k,
+	}, nil
+}
+
+func aes128cfbEncrypt(k key.GeneratedKey, message string) (EncryptedData, error) {
+	block, err := newBlockCipher(k)
+	if err != nil {
+		return EncryptedData{}, err
+	}
 
 	plainText := str.ToBuffer(message)
 	cipherText := str.MakeBuffer(len(plainText))
@@ -64,3 +108,17 @@ func aes128ctrEncrypt(k key.GeneratedKey, message string) (EncryptedData, error)
 		Key:       k,
 	}, nil
 }
+
+func aeadEncrypt(k key.GeneratedKey, message string, additionalData []byte) (EncryptedData, error) {
+	aead, err := NewAEAD(k)
+	if err != nil {
+		return EncryptedData{}, err
+	}
+
+	cipherText := aead.Seal(nil, k.IV, str.ToBuffer(message), additionalData)
+
+	return EncryptedData{
+		Encrypted: cipherText,
+		Key:       k,
+	}, nil
+}
@@ -1,6 +1,8 @@
 package encryption_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/iron-auth/iron-crypto/encryption"
@@ -57,6 +59,119 @@ func TestAes128ctrEncrypt(t *testing.T) {
 	a.EqualsArray(t, data.Key.IV, Aes128ctrGeneratedKey.IV)
 }
 
+func TestAes128ctrEncryptMatchesNodeForLongMessage(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Encrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES128CTR,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes128ctrGeneratedKey.Salt,
+			IV:                Aes128ctrGeneratedKey.IV,
+		},
+	}, DecryptedLongMessage)
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, data.Encrypted, Aes128ctrEncryptedLongMessage)
+}
+
+func TestAes128cfbEncrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Encrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES128CFB,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes128ctrGeneratedKey.Salt,
+			IV:                Aes128ctrGeneratedKey.IV,
+		},
+	}, DecryptedLongMessage)
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, data.Encrypted, Aes128cfbEncryptedLongMessage)
+	a.Equals(t, data.Key.Algorithm, key.AES128CFB)
+}
+
+func TestAes256gcmEncrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.Encrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256gcmGeneratedKey.Salt,
+			IV:                Aes256gcmGeneratedKey.IV,
+		},
+	}, DecryptedMessage)
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, data.Encrypted, Aes256gcmEncryptedPassword)
+
+	a.Equals(t, data.Key.Algorithm, Aes256gcmGeneratedKey.Algorithm)
+	a.EqualsArray(t, data.Key.Key, Aes256gcmGeneratedKey.Key)
+	a.Equals(t, data.Key.Salt, Aes256gcmGeneratedKey.Salt)
+	a.EqualsArray(t, data.Key.IV, Aes256gcmGeneratedKey.IV)
+}
+
+func TestAes256gcmEncryptFailsWithInvalidIV(t *testing.T) {
+	t.Parallel()
+
+	_, err := encryption.Encrypt(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256gcmGeneratedKey.Salt,
+			IV:                Aes256cbcGeneratedKey.IV,
+		},
+	}, DecryptedMessage)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidIV), true)
+}
+
+func TestChacha20poly1305Encrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.EncryptWithAdditionalData(key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.CHACHA20POLY1305,
+			IV:        Chacha20poly1305Nonce,
+		},
+	}, Chacha20Message, Chacha20AdditionalData)
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, data.Encrypted, Chacha20poly1305Encrypted)
+	a.Equals(t, data.Key.Algorithm, key.CHACHA20POLY1305)
+}
+
+func TestXchacha20poly1305Encrypt(t *testing.T) {
+	t.Parallel()
+
+	data, err := encryption.EncryptWithAdditionalData(key.Config{
+		PasswordBuffer: Chacha20Key,
+		Options: key.OptionsConfig{
+			Algorithm: key.XCHACHA20POLY1305,
+			IV:        Xchacha20poly1305Nonce,
+		},
+	}, Chacha20Message, Chacha20AdditionalData)
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, data.Encrypted, Xchacha20poly1305Encrypted)
+	a.Equals(t, data.Key.Algorithm, key.XCHACHA20POLY1305)
+}
+
 func TestSha256EncryptReturnsError(t *testing.T) {
 	t.Parallel()
 
@@ -88,5 +203,18 @@ func TestEncryptFailForInvalidPassword(t *testing.T) {
 		},
 	}, DecryptedMessage)
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
+
+func TestEncryptContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := encryption.EncryptContext(ctx, key.Config{
+		Password: DecryptedPassword,
+		Options:  key.DefaultEncryption,
+	}, DecryptedMessage, nil)
+	a.EqualsError(t, err, context.Canceled)
 }
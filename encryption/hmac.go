@@ -1,8 +1,11 @@
 package encryption
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
 
 	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/key"
@@ -20,21 +23,31 @@ type HmacData struct {
 
 // Generate a HMAC digest for the given message.
 func HmacWithPassword(cfg key.Config, message string) (HmacData, error) {
-	k, err := key.Generate(cfg)
+	return HmacWithPasswordContext(context.Background(), cfg, message)
+}
+
+// Generate a HMAC digest for the given message, stopping with the context's error if it is done before the digest
+// is generated.
+func HmacWithPasswordContext(ctx context.Context, cfg key.Config, message string) (HmacData, error) {
+	k, err := key.GenerateContext(ctx, cfg)
 	if err != nil {
 		return HmacData{}, err
 	}
 
 	switch cfg.Options.Algorithm {
 	case key.SHA256:
-		return sha256Hmac(k, message)
+		return hmacDigest(sha256.New, k, message)
+	case key.SHA384:
+		return hmacDigest(sha512.New384, k, message)
+	case key.SHA512:
+		return hmacDigest(sha512.New, k, message)
 	default:
 		return HmacData{}, ironerrors.ErrInvalidHmacAlgorithm
 	}
 }
 
-func sha256Hmac(k key.GeneratedKey, message string) (HmacData, error) {
-	mac := hmac.New(sha256.New, k.Key)
+func hmacDigest(h func() hash.Hash, k key.GeneratedKey, message string) (HmacData, error) {
+	mac := hmac.New(h, k.Key)
 
 	_, err := mac.Write(str.ToBuffer(message))
 
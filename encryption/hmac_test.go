@@ -46,3 +46,43 @@ func TestHmacWithValidAlgo(t *testing.T) {
 	a.Equals(t, hmac.Digest, GeneratedHmac.Digest)
 	a.Equals(t, hmac.Salt, GeneratedHmac.Salt)
 }
+
+func TestHmacWithSha384MatchesNode(t *testing.T) {
+	t.Parallel()
+
+	hmac, err := encryption.HmacWithPassword(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.SHA384,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Sha384GeneratedHmac.Salt,
+		},
+	}, DecryptedMessage)
+
+	a.EqualsError(t, err, nil)
+
+	a.Equals(t, hmac.Digest, Sha384GeneratedHmac.Digest)
+	a.Equals(t, hmac.Salt, Sha384GeneratedHmac.Salt)
+}
+
+func TestHmacWithSha512MatchesNode(t *testing.T) {
+	t.Parallel()
+
+	hmac, err := encryption.HmacWithPassword(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.SHA512,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Sha512GeneratedHmac.Salt,
+		},
+	}, DecryptedMessage)
+
+	a.EqualsError(t, err, nil)
+
+	a.Equals(t, hmac.Digest, Sha512GeneratedHmac.Digest)
+	a.Equals(t, hmac.Salt, Sha512GeneratedHmac.Salt)
+}
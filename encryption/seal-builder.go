@@ -1,9 +1,11 @@
 package encryption
 
 import (
+	"context"
 	"crypto/subtle"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/key"
@@ -13,6 +15,9 @@ import (
 
 const (
 	macPrefix string = "Fe26.2"
+
+	// Version of the seal format, at the start of every seal's prefix.
+	Version = macPrefix
 )
 
 // Builder for creating and parsing seals.
@@ -22,6 +27,7 @@ type SealBuilder struct {
 	IV         string
 	B64        string
 	Expiration int64
+	Params     SealParams
 	macSalt    string
 	macDigest  string
 
@@ -34,20 +40,48 @@ func (sb *SealBuilder) GetHmacSalt() string {
 	return sb.macSalt
 }
 
+// Retrieve the stored HMAC digest.
+func (sb *SealBuilder) GetHmacDigest() string {
+	return sb.macDigest
+}
+
+// Retrieve the seal's prefix, including any extension parameters.
+func (sb *SealBuilder) GetPrefix() string {
+	return sb.prefix()
+}
+
+func (sb *SealBuilder) prefix() string {
+	return macPrefix + sb.Params.String()
+}
+
+func (sb *SealBuilder) expiration() string {
+	return utils.Ternary(sb.Expiration > 0, strconv.FormatInt(sb.Expiration, 10), "")
+}
+
 func (sb *SealBuilder) buildHmacBase() {
-	exp := utils.Ternary(sb.Expiration > 0, strconv.FormatInt(sb.Expiration, 10), "")
+	sb.macBase = sb.prefix() + "*" + sb.Id + "*" + sb.Salt + "*" + sb.IV + "*" + sb.B64 + "*" + sb.expiration()
+}
+
+// Retrieve the header fields that are authenticated as additional data in an AEAD seal.
+func (sb SealBuilder) AdditionalData() []byte {
+	sb.Params.AEAD = true
 
-	sb.macBase = macPrefix + "*" + sb.Id + "*" + sb.Salt + "*" + sb.IV + "*" + sb.B64 + "*" + exp
+	return str.ToBuffer(sb.prefix() + "*" + sb.Id + "*" + sb.Salt + "*" + sb.expiration())
 }
 
-func (sb *SealBuilder) retrieveHmac(keyCfg key.Config) (HmacData, error) {
+func (sb *SealBuilder) retrieveHmac(ctx context.Context, keyCfg key.Config) (HmacData, error) {
 	sb.buildHmacBase()
-	return HmacWithPassword(keyCfg, sb.macBase)
+	return HmacWithPasswordContext(ctx, keyCfg, sb.macBase)
 }
 
 // Build a new seal.
 func (sb SealBuilder) Build(keyCfg key.Config) (string, error) {
-	mac, err := sb.retrieveHmac(keyCfg)
+	return sb.BuildContext(context.Background(), keyCfg)
+}
+
+// Build a new seal, stopping with the context's error if it is done before the HMAC is generated.
+func (sb SealBuilder) BuildContext(ctx context.Context, keyCfg key.Config) (string, error) {
+	mac, err := sb.retrieveHmac(ctx, keyCfg)
 	if err != nil {
 		return "", err
 	}
@@ -59,16 +93,48 @@ func (sb SealBuilder) Build(keyCfg key.Config) (string, error) {
 	return sb.seal, nil
 }
 
+// Build a new AEAD seal.
+//
+// The encrypted payload already authenticates itself and the additional data, so no HMAC is appended.
+func (sb SealBuilder) BuildAEAD() string {
+	sb.Params.AEAD = true
+
+	sb.seal = sb.prefix() + "*" + sb.Id + "*" + sb.Salt + "*" + sb.IV + "*" + sb.B64 + "*" + sb.expiration()
+	return sb.seal
+}
+
+// Parse a seal, checking it has not expired.
+//
+// The seal is not verified, so Verify must be called before trusting any of its fields.
 func (sb *SealBuilder) Parse(sealed string, now int64, timestampSkewSec int) error {
+	skew := utils.Ternary(timestampSkewSec == 0, 60, utils.Ternary(timestampSkewSec == -1, 0, timestampSkewSec))
+
+	return sb.decode(sealed, func(exp int64) bool {
+		return exp <= (now - int64(skew*1000))
+	})
+}
+
+// Decode the fields of a seal without checking its expiration or verifying it.
+func (sb *SealBuilder) Decode(sealed string) error {
+	return sb.decode(sealed, func(exp int64) bool {
+		return false
+	})
+}
+
+func (sb *SealBuilder) decode(sealed string, isExpired func(exp int64) bool) error {
 	parts := strings.Split(sealed, "*")
-	if len(parts) != 8 {
-		return ironerrors.ErrInvalidSeal
+
+	params, ok := parseSealPrefix(parts[0])
+	if !ok {
+		return invalidSeal(ironerrors.ReasonMalformed, ironerrors.FieldPrefix, "")
 	}
 
-	if parts[0] != macPrefix {
-		return ironerrors.ErrInvalidSeal
+	if len(parts) != utils.Ternary(params.AEAD, 6, 8) {
+		return invalidSeal(ironerrors.ReasonMalformed, ironerrors.FieldNone, "")
 	}
 
+	sb.Params = params
+
 	sb.Id = parts[1]
 	sb.Salt = parts[2]
 	sb.IV = parts[3]
@@ -77,23 +143,58 @@ func (sb *SealBuilder) Parse(sealed string, now int64, timestampSkewSec int) err
 	if parts[5] != "" {
 		exp, err := strconv.ParseInt(parts[5], 10, 64)
 		if err != nil {
-			return ironerrors.ErrInvalidSeal
+			return invalidSeal(ironerrors.ReasonInvalidField, ironerrors.FieldExpiration, sb.Id)
 		}
 
-		skew := utils.Ternary(timestampSkewSec == 0, 60, utils.Ternary(timestampSkewSec == -1, 0, timestampSkewSec))
-		if exp <= (now - int64(skew*1000)) {
-			return ironerrors.ErrExpiredSeal
+		if isExpired(exp) {
+			return &ironerrors.SealError{
+				Reason:     ironerrors.ReasonExpired,
+				Field:      ironerrors.FieldExpiration,
+				PasswordId: sb.Id,
+				Expiration: time.UnixMilli(exp),
+				Err:        ironerrors.ErrExpiredSeal,
+			}
 		}
 
 		sb.Expiration = exp
 	}
 
-	sb.macSalt = parts[6]
-	sb.macDigest = parts[7]
+	if !params.AEAD {
+		if !isValidDigestLength(parts[7]) {
+			return invalidSeal(ironerrors.ReasonInvalidField, ironerrors.FieldHmac, sb.Id)
+		}
+
+		sb.macSalt = parts[6]
+		sb.macDigest = parts[7]
+	}
 
 	return nil
 }
 
+// create an error for a seal that could not be decoded
+func invalidSeal(reason ironerrors.Reason, field int, passwordId string) error {
+	return &ironerrors.SealError{
+		Reason:     reason,
+		Field:      field,
+		PasswordId: passwordId,
+		Err:        ironerrors.ErrInvalidSeal,
+	}
+}
+
+var hmacAlgorithms = []key.Algorithm{key.SHA256, key.SHA384, key.SHA512}
+
+// check the base64 digest has the length of a digest from one of the HMAC algorithms
+func isValidDigestLength(digest string) bool {
+	for _, algo := range hmacAlgorithms {
+		// unpadded base64 uses 4 characters for every 3 bytes
+		if len(digest) == (algo.DigestSize()*4+2)/3 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // NOTE: We are favoring the use of the internal subtle module for constant time comparisons instead of the custom function used in the JS libraries.
 //
 // The following is the GO version of the JS implementation:
@@ -117,13 +218,29 @@ func (sb *SealBuilder) Parse(sealed string, now int64, timestampSkewSec int) err
 
 // Verify a seal.
 func (sb SealBuilder) Verify(keyCfg key.Config) error {
-	mac, err := sb.retrieveHmac(keyCfg)
+	return sb.VerifyContext(context.Background(), keyCfg)
+}
+
+// Verify a seal, stopping with the context's error if it is done before the HMAC is generated.
+func (sb SealBuilder) VerifyContext(ctx context.Context, keyCfg key.Config) error {
+	// a digest from a different HMAC algorithm can never match, so fail before deriving the key
+	if len(sb.macDigest) != (keyCfg.Options.Algorithm.DigestSize()*4+2)/3 {
+		return invalidSeal(ironerrors.ReasonInvalidField, ironerrors.FieldHmac, sb.Id)
+	}
+
+	mac, err := sb.retrieveHmac(ctx, keyCfg)
 	if err != nil {
 		return err
 	}
 
 	if subtle.ConstantTimeCompare(str.ToBuffer(mac.Digest), str.ToBuffer(sb.macDigest)) == 0 {
-		return ironerrors.ErrBadSealHmac
+		return &ironerrors.SealError{
+			Reason:     ironerrors.ReasonBadHmac,
+			Field:      ironerrors.FieldHmac,
+			PasswordId: sb.Id,
+			Expiration: utils.Ternary(sb.Expiration != 0, time.UnixMilli(sb.Expiration), time.Time{}),
+			Err:        ironerrors.ErrBadSealHmac,
+		}
 	}
 
 	return nil
@@ -1,7 +1,10 @@
 package encryption_test
 
 import (
+	"context"
+	"errors"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,13 +50,33 @@ func TestBuild(t *testing.T) {
 	a.EqualsError(t, err, nil)
 }
 
+func TestBuildContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sb := encryption.SealBuilder{
+		Id:   "id",
+		Salt: "salt",
+		IV:   "iv",
+		B64:  "b64",
+	}
+	_, err := sb.BuildContext(ctx, key.Config{
+		Password: DecryptedPassword,
+		Options:  key.DefaultIntegrity,
+	})
+
+	a.EqualsError(t, err, context.Canceled)
+}
+
 func TestParseErrorsOnInvalidSeal(t *testing.T) {
 	t.Parallel()
 
 	sb := encryption.SealBuilder{}
 	err := sb.Parse("invalid", time.Now().UnixMilli(), 0)
 
-	a.EqualsError(t, err, ironerrors.ErrInvalidSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
 }
 
 func TestParseErrorsOnInvalidPrefix(t *testing.T) {
@@ -62,7 +85,7 @@ func TestParseErrorsOnInvalidPrefix(t *testing.T) {
 	sb := encryption.SealBuilder{}
 	err := sb.Parse("prefix*******", time.Now().UnixMilli(), 0)
 
-	a.EqualsError(t, err, ironerrors.ErrInvalidSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
 }
 
 func TestParseErrorsOnInvalidTimestamp(t *testing.T) {
@@ -71,7 +94,13 @@ func TestParseErrorsOnInvalidTimestamp(t *testing.T) {
 	sb := encryption.SealBuilder{}
 	err := sb.Parse("Fe26.2*id*salt*iv*b64*timestamp*macsalt*macdigest", time.Now().UnixMilli(), 0)
 
-	a.EqualsError(t, err, ironerrors.ErrInvalidSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	var sealErr *ironerrors.SealError
+	a.Equals(t, errors.As(err, &sealErr), true)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonInvalidField)
+	a.Equals(t, sealErr.Field, ironerrors.FieldExpiration)
+	a.Equals(t, sealErr.PasswordId, "id")
 }
 
 func TestParseErrorsOnExpiredTimestamp(t *testing.T) {
@@ -80,7 +109,23 @@ func TestParseErrorsOnExpiredTimestamp(t *testing.T) {
 	sb := encryption.SealBuilder{}
 	err := sb.Parse("Fe26.2*id*salt*iv*b64*1*macsalt*macdigest", time.Now().UnixMilli(), 0)
 
-	a.EqualsError(t, err, ironerrors.ErrExpiredSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestDecodeWorksWithExpiredTimestamp(t *testing.T) {
+	t.Parallel()
+
+	sb := encryption.SealBuilder{}
+	err := sb.Decode("Fe26.2~kdf=scrypt*id*salt*iv*b64*1*macsalt*" + GeneratedHmac.Digest)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, sb.Expiration, int64(1))
+	a.Equals(t, sb.GetPrefix(), "Fe26.2~kdf=scrypt")
+	a.Equals(t, sb.GetHmacSalt(), "macsalt")
+	a.Equals(t, sb.GetHmacDigest(), GeneratedHmac.Digest)
+
+	err = sb.Decode("Fe26.2*id*salt*iv*b64*1*macsalt*macdigest")
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
 }
 
 func TestParseWorks(t *testing.T) {
@@ -89,15 +134,183 @@ func TestParseWorks(t *testing.T) {
 	exp := time.Now().UnixMilli() + 1
 
 	sb := encryption.SealBuilder{}
-	err := sb.Parse("Fe26.2*id*salt*iv*b64*"+strconv.FormatInt(exp, 10)+"*macsalt*macdigest", time.Now().UnixMilli(), 0)
+	err := sb.Parse("Fe26.2*id*salt*iv*b64*"+strconv.FormatInt(exp, 10)+"*macsalt*"+GeneratedHmac.Digest, time.Now().UnixMilli(), 0)
+
+	a.Equals(t, err, nil)
+
+	a.Equals(t, sb.Id, "id")
+	a.Equals(t, sb.Salt, "salt")
+	a.Equals(t, sb.IV, "iv")
+	a.Equals(t, sb.B64, "b64")
+	a.Equals(t, sb.Expiration, exp)
+}
+
+func TestParseErrorsOnInvalidDigestLength(t *testing.T) {
+	t.Parallel()
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*macdigest", time.Now().UnixMilli(), 0)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	err = sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*"+GeneratedHmac.Digest+"A", time.Now().UnixMilli(), 0)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	for _, digest := range []string{GeneratedHmac.Digest, Sha384GeneratedHmac.Digest, Sha512GeneratedHmac.Digest} {
+		err = sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*"+digest, time.Now().UnixMilli(), 0)
+
+		a.EqualsError(t, err, nil)
+	}
+}
+
+func TestVerifySucceedsWithSha512(t *testing.T) {
+	t.Parallel()
+
+	integrity := key.OptionsConfig{
+		Algorithm:         key.SHA512,
+		Iterations:        1,
+		MinPasswordLength: 32,
+		SaltBits:          256,
+	}
+
+	sbb := encryption.SealBuilder{
+		Id:   "id",
+		Salt: "salt",
+		IV:   "iv",
+		B64:  "b64",
+	}
+	built, err := sbb.Build(key.Config{
+		Password: DecryptedPassword,
+		Options:  integrity,
+	})
+	a.Equals(t, err, nil)
+
+	sb := encryption.SealBuilder{}
+	err = sb.Parse(built, time.Now().UnixMilli(), 0)
+	a.Equals(t, err, nil)
 
+	integrity.Salt = sb.GetHmacSalt()
+	err = sb.Verify(key.Config{
+		Password: DecryptedPassword,
+		Options:  integrity,
+	})
+	a.Equals(t, err, nil)
+
+	integrity.Algorithm = key.SHA256
+	err = sb.Verify(key.Config{
+		Password: DecryptedPassword,
+		Options:  integrity,
+	})
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	var sealErr *ironerrors.SealError
+	a.Equals(t, errors.As(err, &sealErr), true)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonInvalidField)
+	a.Equals(t, sealErr.Field, ironerrors.FieldHmac)
+}
+
+func TestVerifyFailsWithMismatchedDigestLengthBeforeDerivingKey(t *testing.T) {
+	t.Parallel()
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*"+Sha512GeneratedHmac.Digest, time.Now().UnixMilli(), 0)
 	a.Equals(t, err, nil)
 
+	// the key derivation would fail without a password, so the digest length is checked first
+	err = sb.Verify(key.Config{
+		Password: "",
+		Options:  key.DefaultIntegrity,
+	})
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	var sealErr *ironerrors.SealError
+	a.Equals(t, errors.As(err, &sealErr), true)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonInvalidField)
+	a.Equals(t, sealErr.Field, ironerrors.FieldHmac)
+	a.Equals(t, sealErr.PasswordId, "id")
+}
+
+func TestParseErrorsOnUnknownParams(t *testing.T) {
+	t.Parallel()
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse("Fe26.2~unknown*id*salt*iv*b64**macsalt*macdigest", time.Now().UnixMilli(), 0)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	err = sb.Parse("Fe26.2~*id*salt*iv*b64**macsalt*macdigest", time.Now().UnixMilli(), 0)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
+
+func TestParseErrorsOnAeadSealWithHmac(t *testing.T) {
+	t.Parallel()
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse("Fe26.2~aead*id*salt*iv*b64**macsalt*macdigest", time.Now().UnixMilli(), 0)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
+
+func TestBuildAeadAndParse(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().UnixMilli() + 100000
+
+	sbb := encryption.SealBuilder{
+		Id:         "id",
+		Salt:       "salt",
+		IV:         "iv",
+		B64:        "b64",
+		Expiration: exp,
+	}
+	built := sbb.BuildAEAD()
+
+	a.Equals(t, built, "Fe26.2~aead*id*salt*iv*b64*"+strconv.FormatInt(exp, 10))
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse(built, time.Now().UnixMilli(), 0)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, sb.Params.AEAD, true)
 	a.Equals(t, sb.Id, "id")
 	a.Equals(t, sb.Salt, "salt")
 	a.Equals(t, sb.IV, "iv")
 	a.Equals(t, sb.B64, "b64")
 	a.Equals(t, sb.Expiration, exp)
+	a.Equals(t, string(sb.AdditionalData()), string(sbb.AdditionalData()))
+}
+
+func TestBuildAndParseWithKdfParams(t *testing.T) {
+	t.Parallel()
+
+	sbb := encryption.SealBuilder{
+		Id:   "id",
+		Salt: "salt",
+		IV:   "iv",
+		B64:  "b64",
+		Params: encryption.SealParams{
+			KDF:          key.Scrypt,
+			IntegrityKDF: key.Argon2id,
+		},
+	}
+	built, err := sbb.Build(key.Config{
+		Password: DecryptedPassword,
+		Options:  key.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.HasPrefix(built, "Fe26.2~kdf=scrypt~ikdf=argon2id*"), true)
+
+	sb := encryption.SealBuilder{}
+	err = sb.Parse(built, time.Now().UnixMilli(), 0)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, sb.Params.KDF, key.Scrypt)
+	a.Equals(t, sb.Params.IntegrityKDF, key.Argon2id)
+
+	err = sb.Parse(strings.Replace(built, "scrypt", "bcrypt", 1), time.Now().UnixMilli(), 0)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
 }
 
 // func fixedTimeComparison(oldDigest string, newDigest string) bool {
@@ -135,28 +348,30 @@ func TestVerifyFailsRetrievingHmac(t *testing.T) {
 	t.Parallel()
 
 	sb := encryption.SealBuilder{}
-	sb.Parse("Fe26.2*id*salt*iv*b64*1*macsalt*macdigest", time.Now().UnixMilli(), 0)
+	err := sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*"+GeneratedHmac.Digest, time.Now().UnixMilli(), 0)
+	a.Equals(t, err, nil)
 
-	err := sb.Verify(key.Config{
+	err = sb.Verify(key.Config{
 		Password: "",
 		Options:  key.DefaultIntegrity,
 	})
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestVerifyFailsTimeCompare(t *testing.T) {
 	t.Parallel()
 
 	sb := encryption.SealBuilder{}
-	sb.Parse("Fe26.2*id*salt*iv*b64*1*macsalt*macdigest", time.Now().UnixMilli(), 0)
+	err := sb.Parse("Fe26.2*id*salt*iv*b64**macsalt*"+GeneratedHmac.Digest, time.Now().UnixMilli(), 0)
+	a.Equals(t, err, nil)
 
-	err := sb.Verify(key.Config{
+	err = sb.Verify(key.Config{
 		Password: DecryptedPassword,
 		Options:  key.DefaultIntegrity,
 	})
 
-	a.Equals(t, err, ironerrors.ErrBadSealHmac)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
 }
 
 func TestVerifyFailsTimeCompareWithDifferentSalts(t *testing.T) {
@@ -189,7 +404,7 @@ func TestVerifyFailsTimeCompareWithDifferentSalts(t *testing.T) {
 		},
 	})
 
-	a.Equals(t, err, ironerrors.ErrBadSealHmac)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
 }
 
 func TestVerifySucceeds(t *testing.T) {
@@ -224,3 +439,28 @@ func TestVerifySucceeds(t *testing.T) {
 
 	a.Equals(t, err, nil)
 }
+
+func TestBuildAndParseWithCodecParam(t *testing.T) {
+	t.Parallel()
+
+	sbb := encryption.SealBuilder{
+		Id:   "id",
+		Salt: "salt",
+		IV:   "iv",
+		B64:  "b64",
+		Params: encryption.SealParams{
+			Codec: "cbor",
+		},
+	}
+	built := sbb.BuildAEAD()
+	a.Equals(t, built, "Fe26.2~aead~codec=cbor*id*salt*iv*b64*")
+
+	sb := encryption.SealBuilder{}
+	err := sb.Parse(built, time.Now().UnixMilli(), 0)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, sb.Params.Codec, "cbor")
+
+	err = sb.Parse("Fe26.2~aead~codec=*id*salt*iv*b64*", time.Now().UnixMilli(), 0)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"strings"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/key"
+)
+
+const (
+	paramSeparator string = "~"
+	valueSeparator string = "="
+
+	aeadParam         string = "aead"
+	kdfParam          string = "kdf"
+	integrityKdfParam string = "ikdf"
+	codecParam        string = "codec"
+	zipParam          string = "zip"
+)
+
+// Extension parameters recorded in the seal prefix.
+//
+// Seals without any parameters use the plain prefix and can still be unsealed by @hapi/iron.
+type SealParams struct {
+	// Whether the seal was encrypted with an AEAD algorithm instead of carrying a HMAC.
+	AEAD bool
+	// KDF used to derive the encryption key.
+	KDF key.KDF
+	// KDF used to derive the integrity key.
+	IntegrityKDF key.KDF
+	// Name of the codec used to marshal the payload. Empty for JSON and raw payloads.
+	Codec string
+	// Name of the compressor used to compress the payload. Empty for uncompressed payloads.
+	Compression string
+}
+
+func (p SealParams) String() string {
+	params := []string{}
+
+	if p.AEAD {
+		params = append(params, aeadParam)
+	}
+	if p.KDF != key.PBKDF2SHA1 {
+		params = append(params, kdfParam+valueSeparator+p.KDF.String())
+	}
+	if p.IntegrityKDF != key.PBKDF2SHA1 {
+		params = append(params, integrityKdfParam+valueSeparator+p.IntegrityKDF.String())
+	}
+	if p.Codec != "" {
+		params = append(params, codecParam+valueSeparator+p.Codec)
+	}
+	if p.Compression != "" {
+		params = append(params, zipParam+valueSeparator+p.Compression)
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+
+	return paramSeparator + strings.Join(params, paramSeparator)
+}
+
+func parseSealParams(raw string) (SealParams, bool) {
+	p := SealParams{}
+
+	for _, param := range strings.Split(raw, paramSeparator) {
+		name, value, _ := strings.Cut(param, valueSeparator)
+
+		switch name {
+		case aeadParam:
+			p.AEAD = true
+		case kdfParam, integrityKdfParam:
+			kdf, ok := key.ParseKDF(value)
+			if !ok {
+				return p, false
+			}
+
+			if name == kdfParam {
+				p.KDF = kdf
+			} else {
+				p.IntegrityKDF = kdf
+			}
+		case codecParam:
+			if codec.ValidateName(value) != nil {
+				return p, false
+			}
+
+			p.Codec = value
+		case zipParam:
+			if compress.ValidateName(value) != nil {
+				return p, false
+			}
+
+			p.Compression = value
+		default:
+			return p, false
+		}
+	}
+
+	return p, true
+}
+
+func parseSealPrefix(prefix string) (SealParams, bool) {
+	if prefix == macPrefix {
+		return SealParams{}, true
+	}
+
+	if !strings.HasPrefix(prefix, macPrefix+paramSeparator) {
+		return SealParams{}, false
+	}
+
+	return parseSealParams(strings.TrimPrefix(prefix, macPrefix+paramSeparator))
+}
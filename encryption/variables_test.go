@@ -6,8 +6,9 @@ import (
 )
 
 var (
-	DecryptedPassword = "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword"
-	DecryptedMessage  = "Hello World!"
+	DecryptedPassword    = "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword"
+	DecryptedMessage     = "Hello World!"
+	DecryptedLongMessage = "Hello World! This message is longer than a single AES block."
 )
 
 var (
@@ -27,6 +28,18 @@ var (
 		IV:        []byte{0xac, 0xc6, 0x9d, 0x62, 0x8a, 0x2b, 0x0e, 0x54, 0x55, 0x30, 0xd5, 0x82, 0xed, 0xdc, 0x49, 0x27},
 	}
 
+	// CTR and CFB only differ after the first block, so these use the longer message.
+	Aes128ctrEncryptedLongMessage = []byte{0xa2, 0x0a, 0x05, 0xf5, 0x63, 0xc9, 0x03, 0x12, 0x47, 0xa0, 0x9a, 0xcf, 0x21, 0x00, 0xf6, 0x91, 0x7e, 0x0b, 0x27, 0x2d, 0x8e, 0x22, 0xfd, 0x46, 0xd2, 0x64, 0xea, 0x6e, 0x69, 0x02, 0xf0, 0x52, 0xb1, 0x44, 0xc6, 0x28, 0x29, 0x04, 0x7f, 0x51, 0x39, 0xf1, 0xa6, 0x8a, 0xbd, 0x1d, 0xaf, 0x01, 0x7e, 0x64, 0x8d, 0x2f, 0x65, 0x84, 0xc8, 0xfb, 0xa1, 0x4f, 0x6b, 0xd9}
+	Aes128cfbEncryptedLongMessage = []byte{0xa2, 0x0a, 0x05, 0xf5, 0x63, 0xc9, 0x03, 0x12, 0x47, 0xa0, 0x9a, 0xcf, 0x21, 0x00, 0xf6, 0x91, 0x15, 0x8c, 0x26, 0x92, 0x4a, 0x0f, 0x9c, 0x97, 0xd2, 0xfe, 0xb0, 0xef, 0x5b, 0x30, 0x21, 0xe5, 0x05, 0xc8, 0x9a, 0x3f, 0x04, 0xa6, 0x18, 0xfc, 0xd7, 0xb6, 0xc2, 0xf3, 0xae, 0x3a, 0x2c, 0x0c, 0x29, 0x34, 0x97, 0x6d, 0xab, 0x78, 0xd0, 0x6a, 0x0a, 0x28, 0xed, 0x66}
+
+	Aes256gcmEncryptedPassword = []byte{0x91, 0xf7, 0xa9, 0xa7, 0xf2, 0x12, 0x23, 0x8c, 0x0c, 0x5f, 0xf2, 0x70, 0x73, 0x0b, 0x30, 0x24, 0x2d, 0x23, 0x72, 0x6c, 0xc1, 0x0a, 0xce, 0x64, 0x53, 0xd6, 0x36, 0xcd}
+	Aes256gcmGeneratedKey      = key.GeneratedKey{
+		Algorithm: key.AES256GCM,
+		Key:       []byte{0xf3, 0x23, 0x9f, 0x37, 0x55, 0x29, 0x34, 0xdd, 0xfb, 0xb3, 0x61, 0xbe, 0xa4, 0x7a, 0xab, 0xc7, 0x6f, 0x62, 0x1e, 0xd2, 0x49, 0x25, 0x0e, 0x1d, 0x9d, 0xf5, 0x38, 0x20, 0x4b, 0xf1, 0x63, 0x47},
+		Salt:      "b27a06366ace6bb1560ea039a5595c352a429b87f3982542da9e830a32f5468e",
+		IV:        []byte{0xac, 0xc6, 0x9d, 0x62, 0x8a, 0x2b, 0x0e, 0x54, 0x55, 0x30, 0xd5, 0x82},
+	}
+
 	Sha256GeneratedKey = key.GeneratedKey{
 		Algorithm: key.SHA256,
 		Key:       []byte{0xf3, 0x23, 0x9f, 0x37, 0x55, 0x29, 0x34, 0xdd, 0xfb, 0xb3, 0x61, 0xbe, 0xa4, 0x7a, 0xab, 0xc7, 0x6f, 0x62, 0x1e, 0xd2, 0x49, 0x25, 0x0e, 0x1d, 0x9d, 0xf5, 0x38, 0x20, 0x4b, 0xf1, 0x63, 0x47},
@@ -35,9 +48,30 @@ var (
 	}
 )
 
+// Test vectors from RFC 8439 section 2.8.2 and draft-irtf-cfrg-xchacha-03 appendix A.3.1.
+var (
+	Chacha20Key            = []byte{0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x8a, 0x8b, 0x8c, 0x8d, 0x8e, 0x8f, 0x90, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98, 0x99, 0x9a, 0x9b, 0x9c, 0x9d, 0x9e, 0x9f}
+	Chacha20Message        = "Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it."
+	Chacha20AdditionalData = []byte{0x50, 0x51, 0x52, 0x53, 0xc0, 0xc1, 0xc2, 0xc3, 0xc4, 0xc5, 0xc6, 0xc7}
+
+	Chacha20poly1305Nonce     = []byte{0x07, 0x00, 0x00, 0x00, 0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47}
+	Chacha20poly1305Encrypted = []byte{0xd3, 0x1a, 0x8d, 0x34, 0x64, 0x8e, 0x60, 0xdb, 0x7b, 0x86, 0xaf, 0xbc, 0x53, 0xef, 0x7e, 0xc2, 0xa4, 0xad, 0xed, 0x51, 0x29, 0x6e, 0x08, 0xfe, 0xa9, 0xe2, 0xb5, 0xa7, 0x36, 0xee, 0x62, 0xd6, 0x3d, 0xbe, 0xa4, 0x5e, 0x8c, 0xa9, 0x67, 0x12, 0x82, 0xfa, 0xfb, 0x69, 0xda, 0x92, 0x72, 0x8b, 0x1a, 0x71, 0xde, 0x0a, 0x9e, 0x06, 0x0b, 0x29, 0x05, 0xd6, 0xa5, 0xb6, 0x7e, 0xcd, 0x3b, 0x36, 0x92, 0xdd, 0xbd, 0x7f, 0x2d, 0x77, 0x8b, 0x8c, 0x98, 0x03, 0xae, 0xe3, 0x28, 0x09, 0x1b, 0x58, 0xfa, 0xb3, 0x24, 0xe4, 0xfa, 0xd6, 0x75, 0x94, 0x55, 0x85, 0x80, 0x8b, 0x48, 0x31, 0xd7, 0xbc, 0x3f, 0xf4, 0xde, 0xf0, 0x8e, 0x4b, 0x7a, 0x9d, 0xe5, 0x76, 0xd2, 0x65, 0x86, 0xce, 0xc6, 0x4b, 0x61, 0x16, 0x1a, 0xe1, 0x0b, 0x59, 0x4f, 0x09, 0xe2, 0x6a, 0x7e, 0x90, 0x2e, 0xcb, 0xd0, 0x60, 0x06, 0x91}
+
+	Xchacha20poly1305Nonce     = []byte{0x40, 0x41, 0x42, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f, 0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57}
+	Xchacha20poly1305Encrypted = []byte{0xbd, 0x6d, 0x17, 0x9d, 0x3e, 0x83, 0xd4, 0x3b, 0x95, 0x76, 0x57, 0x94, 0x93, 0xc0, 0xe9, 0x39, 0x57, 0x2a, 0x17, 0x00, 0x25, 0x2b, 0xfa, 0xcc, 0xbe, 0xd2, 0x90, 0x2c, 0x21, 0x39, 0x6c, 0xbb, 0x73, 0x1c, 0x7f, 0x1b, 0x0b, 0x4a, 0xa6, 0x44, 0x0b, 0xf3, 0xa8, 0x2f, 0x4e, 0xda, 0x7e, 0x39, 0xae, 0x64, 0xc6, 0x70, 0x8c, 0x54, 0xc2, 0x16, 0xcb, 0x96, 0xb7, 0x2e, 0x12, 0x13, 0xb4, 0x52, 0x2f, 0x8c, 0x9b, 0xa4, 0x0d, 0xb5, 0xd9, 0x45, 0xb1, 0x1b, 0x69, 0xb9, 0x82, 0xc1, 0xbb, 0x9e, 0x3f, 0x3f, 0xac, 0x2b, 0xc3, 0x69, 0x48, 0x8f, 0x76, 0xb2, 0x38, 0x35, 0x65, 0xd3, 0xff, 0xf9, 0x21, 0xf9, 0x66, 0x4c, 0x97, 0x63, 0x7d, 0xa9, 0x76, 0x88, 0x12, 0xf6, 0x15, 0xc6, 0x8b, 0x13, 0xb5, 0x2e, 0xc0, 0x87, 0x59, 0x24, 0xc1, 0xc7, 0x98, 0x79, 0x47, 0xde, 0xaf, 0xd8, 0x78, 0x0a, 0xcf, 0x49}
+)
+
 var (
 	GeneratedHmac = encryption.HmacData{
 		Digest: "HkofyCetLbMYlMYxNvh3uYNaZRqsGoXgkZHeWsleCZI",
 		Salt:   "b27a06366ace6bb1560ea039a5595c352a429b87f3982542da9e830a32f5468e",
 	}
+	Sha384GeneratedHmac = encryption.HmacData{
+		Digest: "PkoUjaBVaaiYQAQxtBrJDhn-loiD3JjNuL4dXG-hlzjqxqYyM8sqFyOvNbPP_iuo",
+		Salt:   "b27a06366ace6bb1560ea039a5595c352a429b87f3982542da9e830a32f5468e",
+	}
+	Sha512GeneratedHmac = encryption.HmacData{
+		Digest: "KSiKErQ2LzMQIppOQZ_uCxc3E1kXTluyrOUAZcd7pvN5g36jga2PmTALPhXomFC0ASs9-XJC2wzBWAlOoQk2Jw",
+		Salt:   "b27a06366ace6bb1560ea039a5595c352a429b87f3982542da9e830a32f5468e",
+	}
 )
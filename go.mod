@@ -4,4 +4,15 @@ go 1.19
 
 require github.com/james-elicx/go-utils v0.2.4
 
-require golang.org/x/crypto v0.6.0
+require (
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/klauspost/compress v1.16.7
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/crypto v0.6.0
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
@@ -0,0 +1,74 @@
+package iron
+
+import (
+	"time"
+
+	"github.com/iron-auth/iron-crypto/encryption"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+// Details of a seal, read without a password.
+//
+// None of the details are verified, so they must not be trusted until the seal has been unsealed.
+type SealInfo struct {
+	// Prefix of the seal, including any extension parameters.
+	Prefix string
+	// Version of the seal format, such as Fe26.2.
+	Version string
+	// Extension parameters recorded in the prefix.
+	Params encryption.SealParams
+	// ID of the password used to seal.
+	PasswordId string
+	// Time the seal expires. The zero time means it never expires.
+	Expiration time.Time
+	// Salt used to derive the encryption key.
+	Salt string
+	// Salt used to derive the integrity key. Empty for AEAD seals.
+	HmacSalt string
+	// Length in bytes of the IV.
+	IVLength int
+	// Length in bytes of the encrypted payload.
+	PayloadLength int
+	// Length in bytes of the HMAC digest. 0 for AEAD seals.
+	MacLength int
+}
+
+// Read the details of a sealed value without unsealing it.
+//
+// Expired seals are still read. Returns an error if the sealed value is not a valid seal.
+func Inspect(sealed string) (SealInfo, error) {
+	sb, err := parseUnverified(sealed)
+	if err != nil {
+		return SealInfo{}, err
+	}
+
+	iv, err := str.FromBase64(sb.IV)
+	if err != nil {
+		return SealInfo{}, err
+	}
+	payload, err := str.FromBase64(sb.B64)
+	if err != nil {
+		return SealInfo{}, err
+	}
+	mac, err := str.FromBase64(sb.GetHmacDigest())
+	if err != nil {
+		return SealInfo{}, err
+	}
+
+	info := SealInfo{
+		Prefix:        sb.GetPrefix(),
+		Version:       encryption.Version,
+		Params:        sb.Params,
+		PasswordId:    sb.Id,
+		Salt:          sb.Salt,
+		HmacSalt:      sb.GetHmacSalt(),
+		IVLength:      len(iv),
+		PayloadLength: len(payload),
+		MacLength:     len(mac),
+	}
+	if sb.Expiration != 0 {
+		info.Expiration = time.UnixMilli(sb.Expiration)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,90 @@
+package iron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestInspectWorksWithSealFromNode(t *testing.T) {
+	t.Parallel()
+
+	info, err := iron.Inspect(SealedFromNode)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Prefix, "Fe26.2")
+	a.Equals(t, info.Version, "Fe26.2")
+	a.Equals(t, info.Params.AEAD, false)
+	a.Equals(t, info.PasswordId, "")
+	a.Equals(t, info.Expiration.IsZero(), true)
+	a.Equals(t, info.Salt, "6a0a8428b61b9e81c6a6e2556771a9c6a95bf4a68f028c08100e53a5187f8d04")
+	a.Equals(t, info.HmacSalt, "e74b0b23724cb4b9f98e740fbb1d7bf8c6fabaf2bfa256e4ad639651c43c3338")
+	a.Equals(t, info.IVLength, 16)
+	a.Equals(t, info.PayloadLength, 16)
+	a.Equals(t, info.MacLength, 32)
+}
+
+func TestInspectWorksWithExpiredSeal(t *testing.T) {
+	t.Parallel()
+
+	info, err := iron.Inspect(ExpiringSealedFromNode)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Expiration.Equal(time.UnixMilli(1700000060000)), true)
+}
+
+func TestInspectWorksWithAeadSeal(t *testing.T) {
+	t.Parallel()
+
+	encryption := iron.DefaultEncryption
+	encryption.Algorithm = key.AES256GCM
+	encryption.KDF = key.PBKDF2SHA256
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "current",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, iron.SealConfig{
+		Encryption: encryption,
+		Integrity:  iron.DefaultIntegrity,
+		Codec:      codec.CBOR,
+		TTL:        1000,
+		Clock:      clocktest.NewFake(ttlNow),
+	})
+	a.Equals(t, err, nil)
+
+	info, err := iron.Inspect(sealed)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Prefix, "Fe26.2~aead~kdf=pbkdf2-sha256~codec=cbor")
+	a.Equals(t, info.Version, "Fe26.2")
+	a.Equals(t, info.Params.AEAD, true)
+	a.Equals(t, info.Params.KDF, key.PBKDF2SHA256)
+	a.Equals(t, info.Params.Codec, "cbor")
+	a.Equals(t, info.PasswordId, "current")
+	a.Equals(t, info.Expiration.Equal(ttlNow.Add(time.Second)), true)
+	a.Equals(t, info.HmacSalt, "")
+	a.Equals(t, info.IVLength, 12)
+	// CBOR encodes the 12 character string with a 1 byte header, and GCM adds a 16 byte tag
+	a.Equals(t, info.PayloadLength, 13+16)
+	a.Equals(t, info.MacLength, 0)
+}
+
+func TestInspectFailsWithInvalidSeal(t *testing.T) {
+	t.Parallel()
+
+	_, err := iron.Inspect("invalid")
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	_, err = iron.Inspect(InvalidB64SealedFromGo)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBase64Decode), true)
+}
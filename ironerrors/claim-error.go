@@ -0,0 +1,23 @@
+package ironerrors
+
+// An error validating the registered claims in a seal's payload.
+//
+// Matches the sentinel error it wraps with errors.Is.
+type ClaimError struct {
+	// Name of the claim that failed validation, such as "aud". Empty if the payload has no claims at all.
+	Claim string
+	// The sentinel error.
+	Err error
+}
+
+func (e *ClaimError) Error() string {
+	if e.Claim == "" {
+		return e.Err.Error()
+	}
+
+	return e.Err.Error() + " (claim: " + e.Claim + ")"
+}
+
+func (e *ClaimError) Unwrap() error {
+	return e.Err
+}
@@ -18,6 +18,9 @@ var (
 	ErrPasswordTooShort       = errors.New("password is too short")
 	ErrPasswordBufferTooShort = errors.New("password buffer is too short")
 	ErrMissingSalt            = errors.New("missing salt and salt bits")
+	ErrKeyExpired             = errors.New("password is past its not-after time")
+	ErrUnsupportedKDF         = errors.New("unsupported key derivation function")
+	ErrInvalidKDFParams       = errors.New("invalid key derivation function parameters")
 
 	// seal
 
@@ -27,10 +30,49 @@ var (
 	ErrBadSealHmac         = errors.New("bad seal hmac value")
 	ErrMarshallingObject   = errors.New("error marshalling object")
 	ErrUnmarshallingObject = errors.New("error unmarshalling object")
+	ErrUnexpectedCodec     = errors.New("seal payload uses an unexpected codec")
+	ErrMaxAgeExceeded      = errors.New("rolling seal is older than its maximum age")
+
+	// claims
+
+	ErrInvalidIssuer     = errors.New("claims issuer is not the expected issuer")
+	ErrInvalidAudience   = errors.New("claims audience is not an allowed audience")
+	ErrClaimsNotYetValid = errors.New("claims are not valid yet")
+	ErrClaimsTooOld      = errors.New("claims were issued longer ago than the maximum age")
+	ErrMissingClaim      = errors.New("claims are missing a required claim")
+
+	// codecs
+
+	ErrInvalidCodec = errors.New("invalid codec name")
+
+	// compression
+
+	ErrInvalidCompressor           = errors.New("invalid compressor name")
+	ErrCompressorAlreadyRegistered = errors.New("compressor is already registered")
+	ErrUnsupportedCompression      = errors.New("seal payload uses an unsupported compression")
+	ErrCompressing                 = errors.New("error compressing payload")
+	ErrDecompressing               = errors.New("error decompressing payload")
+	ErrDecompressedTooLarge        = errors.New("decompressed payload is too large")
+
+	// streams
+
+	ErrInvalidStreamHeader = errors.New("invalid stream header")
+	ErrInvalidChunkSize    = errors.New("invalid stream chunk size")
+	ErrStreamTooLong       = errors.New("stream has too many chunks")
+	ErrStreamTruncated     = errors.New("stream ended before its final chunk")
+	ErrStreamClosed        = errors.New("stream is closed")
+
+	// cookies
+
+	ErrCookieTooLarge      = errors.New("cookie value is larger than the maximum size")
+	ErrInvalidCookieChunks = errors.New("cookie chunks are missing or invalid")
 
 	// generating values
 
 	ErrCreatingCipher  = errors.New("error creating cipher")
+	ErrInvalidIV       = errors.New("invalid iv size for algorithm")
+	ErrDecrypting      = errors.New("error decrypting, the data may have been tampered with")
+	ErrInvalidPadding  = errors.New("invalid padding, the data may have been tampered with")
 	ErrGeneratingSalt  = errors.New("error generating salt")
 	ErrGeneratingBytes = errors.New("error generating bytes")
 	ErrBase64Decode    = errors.New("error base64 decoding, check input is valid base64")
@@ -0,0 +1,101 @@
+package ironerrors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reason a seal could not be created or unsealed.
+type Reason int
+
+const (
+	// The seal does not have the expected structure.
+	ReasonMalformed Reason = iota + 1
+	// A field in the seal could not be decoded.
+	ReasonInvalidField
+	// The seal has expired.
+	ReasonExpired
+	// The seal's HMAC does not match its contents.
+	ReasonBadHmac
+	// No password was found for the seal's password ID.
+	ReasonPasswordNotFound
+	// The password for the seal's password ID is no longer accepted.
+	ReasonPasswordExpired
+	// The password does not meet the requirements in the options.
+	ReasonInvalidPassword
+	// The payload could not be decrypted.
+	ReasonDecryptFailed
+)
+
+var (
+	reasonNames = map[Reason]string{
+		ReasonMalformed:        "malformed",
+		ReasonInvalidField:     "invalid field",
+		ReasonExpired:          "expired",
+		ReasonBadHmac:          "bad hmac",
+		ReasonPasswordNotFound: "password not found",
+		ReasonPasswordExpired:  "password expired",
+		ReasonInvalidPassword:  "invalid password",
+		ReasonDecryptFailed:    "decrypt failed",
+	}
+)
+
+func (r Reason) String() string {
+	if name, ok := reasonNames[r]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Index of each '*' separated field in a seal.
+const (
+	// Not about a specific field.
+	FieldNone = -1
+
+	FieldPrefix     = 0
+	FieldPasswordId = 1
+	FieldSalt       = 2
+	FieldIV         = 3
+	FieldPayload    = 4
+	FieldExpiration = 5
+	FieldHmacSalt   = 6
+	FieldHmac       = 7
+)
+
+// An error creating or unsealing a seal, with details about the seal.
+//
+// Matches the sentinel error it wraps with errors.Is.
+type SealError struct {
+	// Why the seal failed.
+	Reason Reason
+	// Index of the field in the seal that caused the error, or FieldNone.
+	Field int
+	// ID of the password for the seal, if it is known.
+	PasswordId string
+	// Time the seal or its password expires, if it is known.
+	Expiration time.Time
+	// The sentinel error.
+	Err error
+}
+
+func (e *SealError) Error() string {
+	details := []string{"reason: " + e.Reason.String()}
+
+	if e.Field != FieldNone {
+		details = append(details, fmt.Sprintf("field: %d", e.Field))
+	}
+	if e.PasswordId != "" {
+		details = append(details, fmt.Sprintf("password id: %q", e.PasswordId))
+	}
+	if !e.Expiration.IsZero() {
+		details = append(details, "expiration: "+e.Expiration.UTC().Format(time.RFC3339Nano))
+	}
+
+	return e.Err.Error() + " (" + strings.Join(details, ", ") + ")"
+}
+
+func (e *SealError) Unwrap() error {
+	return e.Err
+}
@@ -10,23 +10,69 @@ const (
 	AES128CTR
 	// SHA-256.
 	SHA256
+	// AES-256-GCM.
+	AES256GCM
+	// ChaCha20-Poly1305.
+	CHACHA20POLY1305
+	// XChaCha20-Poly1305, with an extended nonce that is safe to generate randomly for many messages.
+	XCHACHA20POLY1305
+	// AES-128-CFB.
+	//
+	// Legacy mode that earlier versions of this library used for AES128CTR. Only use it to unseal existing tokens.
+	AES128CFB
+	// SHA-384.
+	SHA384
+	// SHA-512.
+	SHA512
 )
 
 type algorithmData struct {
 	keyBits int
 	ivBits  int
 	name    string
+	aead    bool
 }
 
 var (
 	algorithms = map[Algorithm]algorithmData{
-		AES256CBC: {256, 128, "AES-CBC"},
-		AES128CTR: {128, 128, "AES-CTR"},
-		SHA256:    {256, 0, "SHA-256"},
+		AES256CBC:         {256, 128, "AES-CBC", false},
+		AES128CTR:         {128, 128, "AES-CTR", false},
+		SHA256:            {256, 0, "SHA-256", false},
+		AES256GCM:         {256, 96, "AES-GCM", true},
+		CHACHA20POLY1305:  {256, 96, "ChaCha20-Poly1305", true},
+		XCHACHA20POLY1305: {256, 192, "XChaCha20-Poly1305", true},
+		AES128CFB:         {128, 128, "AES-CFB", false},
+		SHA384:            {384, 0, "SHA-384", false},
+		SHA512:            {512, 0, "SHA-512", false},
 	}
 )
 
 // check algorithm is valid
 func isAlgorithmValid(algo Algorithm) bool {
-	return algo == AES128CTR || algo == AES256CBC || algo == SHA256
+	_, ok := algorithms[algo]
+	return ok
+}
+
+// Whether the algorithm is an AEAD cipher that provides its own integrity.
+func (algo Algorithm) IsAEAD() bool {
+	return algorithms[algo].aead
+}
+
+// Size in bytes of the key used by the algorithm.
+func (algo Algorithm) KeySize() int {
+	return algorithms[algo].keyBits / 8
+}
+
+// Whether the algorithm is a hash used for HMAC integrity.
+func (algo Algorithm) IsHmac() bool {
+	return algo == SHA256 || algo == SHA384 || algo == SHA512
+}
+
+// Size in bytes of the digest produced by a HMAC algorithm, or 0 for other algorithms.
+func (algo Algorithm) DigestSize() int {
+	if !algo.IsHmac() {
+		return 0
+	}
+
+	return algorithms[algo].keyBits / 8
 }
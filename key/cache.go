@@ -0,0 +1,193 @@
+package key
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// identifies a derived key in the cache
+type cacheKey struct {
+	passwordId string
+	// SHA-256 of the password or password buffer, so keys are never shared between passwords with the same ID
+	password   [sha256.Size]byte
+	buffer     bool
+	hkdf       bool
+	salt       string
+	algorithm  Algorithm
+	iterations int
+	kdf        KDF
+	scrypt     ScryptParams
+	argon2     Argon2Params
+	info       string
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	value   []byte
+	expires time.Time
+}
+
+// Statistics for a key cache.
+type CacheStats struct {
+	// Number of lookups that found a derived key.
+	Hits uint64
+	// Number of lookups that had to derive a key.
+	Misses uint64
+	// Number of derived keys removed because the cache was full or they expired.
+	Evictions uint64
+	// Number of derived keys currently in the cache.
+	Size int
+}
+
+// Ratio of lookups that found a derived key, between 0 and 1.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// A bounded LRU cache of derived keys, so unsealing a token with the same salt does not repeat the key derivation.
+//
+// Keys are cached by password ID and a hash of the password, so a key is only found for the password it was derived
+// from. Evicted keys are zeroed. A cache is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[cacheKey]*list.Element
+	order   *list.List
+	stats   CacheStats
+}
+
+// Create a new key cache holding at most size keys, each for at most the ttl.
+//
+// A ttl of 0 keeps keys until they are evicted to make room for others.
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[cacheKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Retrieve the current statistics for the cache.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+
+	return stats
+}
+
+// Retrieve a copy of a cached key.
+func (c *Cache) get(k cacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[k]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if c.ttl > 0 && !time.Now().Before(entry.expires) {
+		c.remove(el)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+
+	return append([]byte(nil), entry.value...), true
+}
+
+// Store a copy of a derived key, evicting the least recently used key if the cache is full.
+func (c *Cache) put(k cacheKey, value []byte) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[k]; ok {
+		// another lookup derived the same key concurrently
+		c.order.MoveToFront(el)
+		return
+	}
+
+	for c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[k] = c.order.PushFront(&cacheEntry{
+		key:     k,
+		value:   append([]byte(nil), value...),
+		expires: time.Now().Add(c.ttl),
+	})
+}
+
+// remove an entry and zero its key
+func (c *Cache) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.key)
+
+	for i := range entry.value {
+		entry.value[i] = 0
+	}
+
+	c.stats.Evictions++
+}
+
+// derive a key, using the cache if there is one and the salt was supplied rather than generated
+func deriveCached(cfg Config, salt string, derive func() ([]byte, error)) ([]byte, error) {
+	if cfg.Cache == nil || cfg.Options.Salt == "" {
+		return derive()
+	}
+
+	k := cacheKey{
+		passwordId: cfg.PasswordId,
+		password:   passwordHash(cfg),
+		buffer:     cfg.Password == "",
+		hkdf:       cfg.HKDF,
+		salt:       salt,
+		algorithm:  cfg.Options.Algorithm,
+		iterations: cfg.Options.Iterations,
+		kdf:        cfg.Options.KDF,
+		scrypt:     cfg.Options.Scrypt,
+		argon2:     cfg.Options.Argon2,
+		info:       cfg.Info,
+	}
+
+	if dk, ok := cfg.Cache.get(k); ok {
+		return dk, nil
+	}
+
+	dk, err := derive()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Cache.put(k, dk)
+
+	return dk, nil
+}
+
+// hash the password that a key is derived from
+func passwordHash(cfg Config) [sha256.Size]byte {
+	if cfg.Password != "" {
+		return sha256.Sum256([]byte(cfg.Password))
+	}
+
+	return sha256.Sum256(cfg.PasswordBuffer)
+}
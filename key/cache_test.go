@@ -0,0 +1,133 @@
+package key_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/key"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func generateCached(t *testing.T, cache *key.Cache, salt string) key.GeneratedKey {
+	k, err := key.Generate(key.Config{
+		Password:   DecryptedPassword,
+		Cache:      cache,
+		PasswordId: "password",
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              salt,
+			IV:                Aes256cbcGeneratedKey.IV,
+		},
+	})
+	a.Equals(t, err, nil)
+
+	return k
+}
+
+func TestCacheReturnsDerivedKey(t *testing.T) {
+	t.Parallel()
+
+	cache := key.NewCache(10, 0)
+
+	k := generateCached(t, cache, Aes256cbcGeneratedKey.Salt)
+	a.EqualsArray(t, k.Key, Aes256cbcGeneratedKey.Key)
+
+	// changing a returned key does not change the cached key
+	k.Key[0] = 0
+
+	k = generateCached(t, cache, Aes256cbcGeneratedKey.Salt)
+	a.EqualsArray(t, k.Key, Aes256cbcGeneratedKey.Key)
+
+	stats := cache.Stats()
+	a.Equals(t, stats.Hits, uint64(1))
+	a.Equals(t, stats.Misses, uint64(1))
+	a.Equals(t, stats.Size, 1)
+	a.Equals(t, stats.HitRatio(), 0.5)
+}
+
+func TestCacheIsNotUsedForGeneratedSalts(t *testing.T) {
+	t.Parallel()
+
+	cache := key.NewCache(10, 0)
+
+	generateCached(t, cache, "")
+	generateCached(t, cache, "")
+
+	stats := cache.Stats()
+	a.Equals(t, stats.Hits, uint64(0))
+	a.Equals(t, stats.Misses, uint64(0))
+	a.Equals(t, stats.Size, 0)
+	a.Equals(t, stats.HitRatio(), 0.0)
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	cache := key.NewCache(2, 0)
+
+	generateCached(t, cache, "salt one")
+	generateCached(t, cache, "salt two")
+	generateCached(t, cache, "salt one")
+	generateCached(t, cache, "salt three")
+
+	stats := cache.Stats()
+	a.Equals(t, stats.Evictions, uint64(1))
+	a.Equals(t, stats.Size, 2)
+
+	// salt two was evicted, salt one was still cached
+	generateCached(t, cache, "salt one")
+	generateCached(t, cache, "salt two")
+
+	stats = cache.Stats()
+	a.Equals(t, stats.Hits, uint64(2))
+	a.Equals(t, stats.Misses, uint64(4))
+}
+
+func TestCacheExpiresKeys(t *testing.T) {
+	t.Parallel()
+
+	cache := key.NewCache(10, 20*time.Millisecond)
+
+	generateCached(t, cache, Aes256cbcGeneratedKey.Salt)
+	time.Sleep(30 * time.Millisecond)
+	k := generateCached(t, cache, Aes256cbcGeneratedKey.Salt)
+
+	a.EqualsArray(t, k.Key, Aes256cbcGeneratedKey.Key)
+
+	stats := cache.Stats()
+	a.Equals(t, stats.Hits, uint64(0))
+	a.Equals(t, stats.Misses, uint64(2))
+	a.Equals(t, stats.Evictions, uint64(1))
+	a.Equals(t, stats.Size, 1)
+}
+
+func TestCacheSeparatesPasswordsWithSameId(t *testing.T) {
+	t.Parallel()
+
+	cache := key.NewCache(10, 0)
+	generateCached(t, cache, Aes256cbcGeneratedKey.Salt)
+
+	k, err := key.Generate(key.Config{
+		Password:   DecryptedPassword + "other",
+		Cache:      cache,
+		PasswordId: "password",
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			IV:                Aes256cbcGeneratedKey.IV,
+		},
+	})
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(k.Key, Aes256cbcGeneratedKey.Key), false)
+
+	stats := cache.Stats()
+	a.Equals(t, stats.Hits, uint64(0))
+	a.Equals(t, stats.Size, 2)
+}
@@ -1,12 +1,11 @@
 package key
 
 import (
-	"crypto/sha1"
+	"context"
 
 	"github.com/iron-auth/iron-crypto/bits"
 	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/str"
-	"golang.org/x/crypto/pbkdf2"
 )
 
 // Key generation options.
@@ -15,16 +14,30 @@ type Config struct {
 	Password string
 	// Password buffer to use. If not specified, the password will be used.
 	PasswordBuffer []byte
+	// Derive the key from the password buffer with HKDF-SHA256 instead of using the buffer directly.
+	HKDF bool
+	// Context label for the HKDF derivation, so different uses of one password buffer get distinct keys.
+	Info string
+	// Cache for derived keys. Only used when the salt is supplied in the options, such as when unsealing.
+	Cache *Cache
+	// ID of the password, used to identify keys in the cache.
+	PasswordId string
 	// Encryption options.
 	Options OptionsConfig
 }
 
 // Encryption options.
 type OptionsConfig struct {
-	// AES128CTR | AES256CBC | SHA256
+	// AES128CTR | AES256CBC | AES256GCM | CHACHA20POLY1305 | XCHACHA20POLY1305 | SHA256 | SHA384 | SHA512
 	Algorithm Algorithm
-	// Total number of iterations to use. More iterations are more secure but slower.
+	// Total number of iterations to use with PBKDF2. More iterations are more secure but slower.
 	Iterations int
+	// Key derivation function to use with a password. Defaults to PBKDF2SHA1 for compatibility with @hapi/iron.
+	KDF KDF
+	// Parameters to use with the Scrypt KDF.
+	Scrypt ScryptParams
+	// Parameters to use with the Argon2id KDF.
+	Argon2 Argon2Params
 	// Minimum password length. Shorter passwords are less secure.
 	MinPasswordLength int
 	// Number of bits to use in the salt. More bits are more secure but slower.
@@ -38,7 +51,7 @@ type OptionsConfig struct {
 
 // Key generation result.
 type GeneratedKey struct {
-	// AES128CTR | AES256CBC | SHA256
+	// AES128CTR | AES256CBC | AES256GCM | CHACHA20POLY1305 | XCHACHA20POLY1305 | SHA256 | SHA384 | SHA512
 	Algorithm Algorithm
 	// Encryption key.
 	Key []byte
@@ -78,11 +91,46 @@ func isOptionsUndefined(options OptionsConfig) bool {
 	return options.Algorithm == 0 && options.Iterations == 0 && options.MinPasswordLength == 0 && options.SaltBits == 0 && options.Salt == "" && options.IV == nil
 }
 
+// Create an error for a password that cannot be used with the options.
+func invalidPassword(cfg Config, err error) error {
+	return &ironerrors.SealError{
+		Reason:     ironerrors.ReasonInvalidPassword,
+		Field:      ironerrors.FieldNone,
+		PasswordId: cfg.PasswordId,
+		Err:        err,
+	}
+}
+
+// Use the salt from the options, or generate a new one.
+func generateSalt(options OptionsConfig) (string, error) {
+	// check salt is specified
+	if options.Salt != "" {
+		return options.Salt, nil
+	}
+
+	if options.SaltBits == 0 {
+		return "", ironerrors.ErrMissingSalt
+	}
+
+	// generate a new salt
+	return bits.RandomSalt(options.SaltBits)
+}
+
 // Generate a key to use for encryption.
 func Generate(cfg Config) (GeneratedKey, error) {
+	return GenerateContext(context.Background(), cfg)
+}
+
+// Generate a key to use for encryption, stopping with the context's error if it is done before or during the key
+// derivation.
+func GenerateContext(ctx context.Context, cfg Config) (GeneratedKey, error) {
+	if err := ctx.Err(); err != nil {
+		return GeneratedKey{}, err
+	}
+
 	// check password is specificed
 	if cfg.Password == "" && cfg.PasswordBuffer == nil {
-		return GeneratedKey{}, ironerrors.ErrPasswordRequired
+		return GeneratedKey{}, invalidPassword(cfg, ironerrors.ErrPasswordRequired)
 	}
 	if isOptionsUndefined(cfg.Options) {
 		return GeneratedKey{}, ironerrors.ErrMissingOptions
@@ -97,37 +145,54 @@ func Generate(cfg Config) (GeneratedKey, error) {
 	if cfg.Password != "" {
 		// check password length is valid
 		if len(cfg.Password) < cfg.Options.MinPasswordLength {
-			return GeneratedKey{}, ironerrors.ErrPasswordTooShort
+			return GeneratedKey{}, invalidPassword(cfg, ironerrors.ErrPasswordTooShort)
 		}
 
-		salt := cfg.Options.Salt
-		// check salt is specified
-		if salt == "" {
-			if cfg.Options.SaltBits == 0 {
-				return GeneratedKey{}, ironerrors.ErrMissingSalt
-			}
-
-			// generate a new salt
-			newSalt, err := bits.RandomSalt(cfg.Options.SaltBits)
-			if err != nil {
-				return GeneratedKey{}, err
-			}
-			salt = newSalt
+		salt, err := generateSalt(cfg.Options)
+		if err != nil {
+			return GeneratedKey{}, err
 		}
 
 		// generate a new key
-		dk := pbkdf2.Key(str.ToBuffer(cfg.Password), str.ToBuffer(salt), cfg.Options.Iterations, algo.keyBits/8, sha1.New)
+		dk, err := deriveCached(cfg, salt, func() ([]byte, error) {
+			return deriveKey(str.ToBuffer(cfg.Password), str.ToBuffer(salt), algo.keyBits/8, cfg.Options)
+		})
+		if err != nil {
+			return GeneratedKey{}, err
+		}
 
 		result.Key = dk
 		result.Salt = salt
 	} else if cfg.PasswordBuffer != nil {
 		// check password length is valid
 		if len(cfg.PasswordBuffer) < algo.keyBits/8 {
-			return GeneratedKey{}, ironerrors.ErrPasswordBufferTooShort
+			return GeneratedKey{}, invalidPassword(cfg, ironerrors.ErrPasswordBufferTooShort)
 		}
 
-		result.Key = cfg.PasswordBuffer
-		result.Salt = ""
+		if cfg.HKDF {
+			salt, err := generateSalt(cfg.Options)
+			if err != nil {
+				return GeneratedKey{}, err
+			}
+
+			dk, err := deriveCached(cfg, salt, func() ([]byte, error) {
+				return deriveSubkey(cfg.PasswordBuffer, str.ToBuffer(salt), cfg.Info, algo.keyBits/8)
+			})
+			if err != nil {
+				return GeneratedKey{}, err
+			}
+
+			result.Key = dk
+			result.Salt = salt
+		} else {
+			result.Key = cfg.PasswordBuffer
+			result.Salt = ""
+		}
+	}
+
+	// the key derivation cannot be interrupted, so check the context again once it has finished
+	if err := ctx.Err(); err != nil {
+		return GeneratedKey{}, err
 	}
 
 	if cfg.Options.IV != nil {
@@ -1,7 +1,10 @@
 package key_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/key"
@@ -12,13 +15,13 @@ func TestMissingPasswordReturnsError(t *testing.T) {
 	t.Parallel()
 
 	_, err := key.Generate(key.Config{})
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 
 	_, err = key.Generate(key.Config{Password: ""})
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 
 	_, err = key.Generate(key.Config{PasswordBuffer: nil})
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestMissingOptionsReturnsError(t *testing.T) {
@@ -55,7 +58,7 @@ func TestPasswordTooShortReturnsError(t *testing.T) {
 		Password: "password",
 		Options:  key.DefaultEncryption,
 	})
-	a.EqualsError(t, err, ironerrors.ErrPasswordTooShort)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordTooShort), true)
 }
 
 func TestNoSaltOrSaltBitsReturnsError(t *testing.T) {
@@ -122,7 +125,7 @@ func TestGeneratesKeyAndSaltWithPasswordBuffer(t *testing.T) {
 		Options:        key.DefaultEncryption,
 	})
 
-	a.EqualsError(t, err, ironerrors.ErrPasswordBufferTooShort)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordBufferTooShort), true)
 
 	// with long enough buffer
 	k, err := key.Generate(key.Config{
@@ -205,3 +208,115 @@ func TestGeneratesKeyMatchingHapiIronForSha256(t *testing.T) {
 	a.Equals(t, k.Salt, Sha256GeneratedKey.Salt)
 	a.EqualsArray(t, k.IV, Sha256GeneratedKey.IV)
 }
+
+func TestGeneratesKeyAndNonceForAes256gcm(t *testing.T) {
+	t.Parallel()
+
+	k, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256GCM,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	isValidKey(t, k, false, key.AES256GCM)
+
+	a.Equals(t, len(k.Key), 32)
+	a.Equals(t, len(k.IV), 12)
+	a.Equals(t, key.AES256GCM.IsAEAD(), true)
+	a.Equals(t, key.AES256CBC.IsAEAD(), false)
+}
+
+func TestGeneratesNonceForChacha20poly1305(t *testing.T) {
+	t.Parallel()
+
+	k, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.CHACHA20POLY1305,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	isValidKey(t, k, false, key.CHACHA20POLY1305)
+	a.Equals(t, len(k.Key), 32)
+	a.Equals(t, len(k.IV), 12)
+
+	k, err = key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.XCHACHA20POLY1305,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	isValidKey(t, k, false, key.XCHACHA20POLY1305)
+	a.Equals(t, len(k.Key), 32)
+	a.Equals(t, len(k.IV), 24)
+}
+
+func TestGeneratesKeysForShaDigestSizes(t *testing.T) {
+	t.Parallel()
+
+	for _, algo := range []key.Algorithm{key.SHA256, key.SHA384, key.SHA512} {
+		k, err := key.Generate(key.Config{
+			Password: DecryptedPassword,
+			Options: key.OptionsConfig{
+				Algorithm:         algo,
+				Iterations:        2,
+				MinPasswordLength: 32,
+				SaltBits:          256,
+			},
+		})
+
+		a.Equals(t, err, nil)
+		a.Equals(t, algo.IsHmac(), true)
+		a.Equals(t, len(k.Key), algo.DigestSize())
+	}
+
+	a.Equals(t, key.AES256CBC.IsHmac(), false)
+	a.Equals(t, key.AES256CBC.DigestSize(), 0)
+}
+
+func TestGenerateContextStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := key.GenerateContext(ctx, key.Config{
+		Password: DecryptedPassword,
+		Options:  key.DefaultEncryption,
+	})
+	a.EqualsError(t, err, context.Canceled)
+}
+
+func TestGenerateContextStopsAfterDerivationPastDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	_, err := key.GenerateContext(ctx, key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+			KDF:               key.Scrypt,
+			Scrypt:            key.ScryptParams{N: 1 << 17},
+		},
+	})
+	a.EqualsError(t, err, context.DeadlineExceeded)
+}
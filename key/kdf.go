@@ -0,0 +1,169 @@
+package key
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"io"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/str"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The key derivation function used to derive a key from a password.
+type KDF int64
+
+const (
+	// PBKDF2 with SHA-1, as used by @hapi/iron.
+	PBKDF2SHA1 KDF = iota
+	// PBKDF2 with SHA-256.
+	PBKDF2SHA256
+	// scrypt.
+	Scrypt
+	// Argon2id.
+	Argon2id
+)
+
+var (
+	kdfNames = map[KDF]string{
+		PBKDF2SHA1:   "pbkdf2-sha1",
+		PBKDF2SHA256: "pbkdf2-sha256",
+		Scrypt:       "scrypt",
+		Argon2id:     "argon2id",
+	}
+)
+
+// Parameters for scrypt. Zero values use the defaults.
+type ScryptParams struct {
+	// CPU/memory cost, a power of two greater than 1. Defaults to 32768.
+	N int
+	// Block size. Defaults to 8.
+	R int
+	// Parallelisation. Defaults to 1.
+	P int
+}
+
+// Parameters for Argon2id. Zero values use the defaults.
+type Argon2Params struct {
+	// Memory to use in KiB. Defaults to 65536 (64 MiB).
+	Memory uint32
+	// Number of passes over the memory. Defaults to 3.
+	Time uint32
+	// Number of threads to use. Defaults to 4.
+	Parallelism uint8
+}
+
+const (
+	// HKDF context label for encryption keys derived from a password buffer.
+	EncryptionInfo = "iron-crypto encryption"
+	// HKDF context label for integrity keys derived from a password buffer.
+	IntegrityInfo = "iron-crypto integrity"
+)
+
+var (
+	// Default scrypt parameters.
+	DefaultScrypt = ScryptParams{
+		N: 32768,
+		R: 8,
+		P: 1,
+	}
+	// Default Argon2id parameters, following the second recommended option in RFC 9106.
+	DefaultArgon2 = Argon2Params{
+		Memory:      64 * 1024,
+		Time:        3,
+		Parallelism: 4,
+	}
+)
+
+// Name of the KDF, as recorded in a seal.
+func (kdf KDF) String() string {
+	return kdfNames[kdf]
+}
+
+// Look up a KDF by the name recorded in a seal.
+func ParseKDF(name string) (KDF, bool) {
+	for kdf, kdfName := range kdfNames {
+		if kdfName == name {
+			return kdf, true
+		}
+	}
+
+	return 0, false
+}
+
+// A set of key derivation functions.
+type KDFSet uint64
+
+// Create a set of the key derivation functions.
+func KDFs(kdfs ...KDF) KDFSet {
+	var set KDFSet
+	for _, kdf := range kdfs {
+		if kdf >= 0 && kdf < 64 {
+			set |= 1 << kdf
+		}
+	}
+
+	return set
+}
+
+// Check whether the set contains the KDF.
+func (s KDFSet) Has(kdf KDF) bool {
+	return kdf >= 0 && kdf < 64 && s&(1<<kdf) != 0
+}
+
+// derive a key of the given length from the password and salt with the configured KDF
+func deriveKey(password []byte, salt []byte, keyLength int, options OptionsConfig) ([]byte, error) {
+	switch options.KDF {
+	case PBKDF2SHA1:
+		return pbkdf2.Key(password, salt, options.Iterations, keyLength, sha1.New), nil
+	case PBKDF2SHA256:
+		return pbkdf2.Key(password, salt, options.Iterations, keyLength, sha256.New), nil
+	case Scrypt:
+		params := options.Scrypt
+		if params.N == 0 {
+			params.N = DefaultScrypt.N
+		}
+		if params.R == 0 {
+			params.R = DefaultScrypt.R
+		}
+		if params.P == 0 {
+			params.P = DefaultScrypt.P
+		}
+
+		dk, err := scrypt.Key(password, salt, params.N, params.R, params.P, keyLength)
+		if err != nil {
+			return nil, ironerrors.ErrInvalidKDFParams
+		}
+
+		return dk, nil
+	case Argon2id:
+		params := options.Argon2
+		if params.Memory == 0 {
+			params.Memory = DefaultArgon2.Memory
+		}
+		if params.Time == 0 {
+			params.Time = DefaultArgon2.Time
+		}
+		if params.Parallelism == 0 {
+			params.Parallelism = DefaultArgon2.Parallelism
+		}
+
+		return argon2.IDKey(password, salt, params.Time, params.Memory, params.Parallelism, uint32(keyLength)), nil
+	default:
+		return nil, ironerrors.ErrUnsupportedKDF
+	}
+}
+
+// derive a subkey of the given length from a master key with HKDF-SHA256
+func deriveSubkey(master []byte, salt []byte, info string, keyLength int) ([]byte, error) {
+	dk := make([]byte, keyLength)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, master, salt, str.ToBuffer(info)), dk); err != nil {
+		return nil, ironerrors.ErrInvalidKDFParams
+	}
+
+	return dk, nil
+}
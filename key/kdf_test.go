@@ -0,0 +1,183 @@
+package key_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestPbkdf2Sha256MatchesNode(t *testing.T) {
+	t.Parallel()
+
+	k, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			KDF:               key.PBKDF2SHA256,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, k.Key, Pbkdf2Sha256Key)
+}
+
+func TestScryptMatchesNode(t *testing.T) {
+	t.Parallel()
+
+	k, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			MinPasswordLength: 32,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			KDF:               key.Scrypt,
+			Scrypt:            key.ScryptParams{N: 1024},
+		},
+	})
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, k.Key, ScryptKey)
+}
+
+func TestScryptReturnsErrorForInvalidParams(t *testing.T) {
+	t.Parallel()
+
+	_, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			MinPasswordLength: 32,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			KDF:               key.Scrypt,
+			Scrypt:            key.ScryptParams{N: 1000},
+		},
+	})
+
+	a.EqualsError(t, err, ironerrors.ErrInvalidKDFParams)
+}
+
+func TestArgon2idGeneratesDistinctKey(t *testing.T) {
+	t.Parallel()
+
+	options := key.OptionsConfig{
+		Algorithm:         key.AES256CBC,
+		MinPasswordLength: 32,
+		Salt:              Aes256cbcGeneratedKey.Salt,
+		KDF:               key.Argon2id,
+		Argon2:            key.Argon2Params{Memory: 1024, Time: 1, Parallelism: 1},
+	}
+
+	k, err := key.Generate(key.Config{Password: DecryptedPassword, Options: options})
+	a.Equals(t, err, nil)
+	a.Equals(t, len(k.Key), 32)
+
+	again, err := key.Generate(key.Config{Password: DecryptedPassword, Options: options})
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, again.Key, k.Key)
+	a.Equals(t, bytes.Equal(k.Key, Aes256cbcGeneratedKey.Key), false)
+}
+
+func TestUnsupportedKDFReturnsError(t *testing.T) {
+	t.Parallel()
+
+	_, err := key.Generate(key.Config{
+		Password: DecryptedPassword,
+		Options: key.OptionsConfig{
+			Algorithm:         key.AES256CBC,
+			MinPasswordLength: 32,
+			Salt:              Aes256cbcGeneratedKey.Salt,
+			KDF:               99,
+		},
+	})
+
+	a.EqualsError(t, err, ironerrors.ErrUnsupportedKDF)
+}
+
+func TestParseKDF(t *testing.T) {
+	t.Parallel()
+
+	for _, kdf := range []key.KDF{key.PBKDF2SHA1, key.PBKDF2SHA256, key.Scrypt, key.Argon2id} {
+		parsed, ok := key.ParseKDF(kdf.String())
+
+		a.Equals(t, ok, true)
+		a.Equals(t, parsed, kdf)
+	}
+
+	_, ok := key.ParseKDF("bcrypt")
+	a.Equals(t, ok, false)
+}
+
+func TestKDFSet(t *testing.T) {
+	t.Parallel()
+
+	set := key.KDFs(key.PBKDF2SHA1, key.Argon2id)
+
+	a.Equals(t, set.Has(key.PBKDF2SHA1), true)
+	a.Equals(t, set.Has(key.Argon2id), true)
+	a.Equals(t, set.Has(key.Scrypt), false)
+	a.Equals(t, set.Has(key.KDF(-1)), false)
+	a.Equals(t, set.Has(key.KDF(64)), false)
+	a.Equals(t, key.KDFs().Has(key.PBKDF2SHA1), false)
+}
+
+func TestHkdfDerivesDistinctSubkeysMatchingNode(t *testing.T) {
+	t.Parallel()
+
+	encryptionKey, err := key.Generate(key.Config{
+		PasswordBuffer: MasterKeyBuffer,
+		HKDF:           true,
+		Info:           key.EncryptionInfo,
+		Options: key.OptionsConfig{
+			Algorithm: key.AES256CBC,
+			Salt:      Aes256cbcGeneratedKey.Salt,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, encryptionKey.Key, HkdfEncryptionKey)
+	a.Equals(t, encryptionKey.Salt, Aes256cbcGeneratedKey.Salt)
+
+	integrityKey, err := key.Generate(key.Config{
+		PasswordBuffer: MasterKeyBuffer,
+		HKDF:           true,
+		Info:           key.IntegrityInfo,
+		Options: key.OptionsConfig{
+			Algorithm: key.SHA256,
+			Salt:      Aes256cbcGeneratedKey.Salt,
+		},
+	})
+
+	a.Equals(t, err, nil)
+	a.EqualsArray(t, integrityKey.Key, HkdfIntegrityKey)
+}
+
+func TestHkdfGeneratesSalt(t *testing.T) {
+	t.Parallel()
+
+	k, err := key.Generate(key.Config{
+		PasswordBuffer: MasterKeyBuffer,
+		HKDF:           true,
+		Info:           key.EncryptionInfo,
+		Options:        key.DefaultEncryption,
+	})
+
+	a.Equals(t, err, nil)
+	isValidKey(t, k, false, key.AES256CBC)
+	a.Equals(t, bytes.Equal(k.Key, MasterKeyBuffer), false)
+
+	_, err = key.Generate(key.Config{
+		PasswordBuffer: MasterKeyBuffer,
+		HKDF:           true,
+		Options: key.OptionsConfig{
+			Algorithm: key.AES256GCM,
+		},
+	})
+
+	a.EqualsError(t, err, ironerrors.ErrMissingSalt)
+}
@@ -37,6 +37,18 @@ var (
 	}
 )
 
+var (
+	Pbkdf2Sha256Key = []byte{0x0b, 0x60, 0xe6, 0x1d, 0x5f, 0x59, 0x3e, 0x0c, 0xb0, 0xe7, 0x0f, 0xae, 0xb7, 0x27, 0x33, 0x57, 0xd9, 0x5f, 0xde, 0x81, 0x0e, 0xaa, 0xbf, 0x52, 0xb8, 0x01, 0x1d, 0x0f, 0x16, 0xf8, 0x8d, 0x7f}
+	// scrypt with N=1024, r=8, p=1.
+	ScryptKey = []byte{0x65, 0x48, 0x31, 0x43, 0x0c, 0xb1, 0x2e, 0x30, 0x52, 0xed, 0x97, 0x2c, 0xaa, 0x4d, 0x61, 0x34, 0x56, 0xf9, 0x26, 0x92, 0x5d, 0xef, 0x6c, 0x0c, 0x86, 0xfe, 0xdf, 0x6d, 0xf4, 0xbc, 0xb3, 0x4c}
+)
+
+var (
+	MasterKeyBuffer   = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}
+	HkdfEncryptionKey = []byte{0x23, 0x42, 0xbe, 0x04, 0x9f, 0x13, 0xbb, 0xe2, 0x46, 0x41, 0xaf, 0x4c, 0xae, 0x53, 0xa6, 0xed, 0xbc, 0x8c, 0x65, 0x8c, 0x33, 0x28, 0xe5, 0xde, 0xef, 0xd6, 0xb2, 0xb6, 0x94, 0xd5, 0x70, 0x70}
+	HkdfIntegrityKey  = []byte{0xd8, 0xaf, 0xba, 0x3a, 0xf5, 0xeb, 0x9c, 0x0f, 0xed, 0xda, 0x8c, 0x29, 0x2c, 0xce, 0xd4, 0x27, 0x15, 0xcf, 0xa1, 0x38, 0x2b, 0xcc, 0x5f, 0xff, 0xe5, 0x08, 0xa7, 0x2e, 0x31, 0x76, 0x4b, 0xc8}
+)
+
 func isValidKey(t *testing.T, k key.GeneratedKey, fromBuffer bool, algo key.Algorithm) {
 	a.NotEquals(t, k.Algorithm, "")
 	a.Equals(t, k.Algorithm, algo)
@@ -0,0 +1,60 @@
+package iron
+
+import (
+	"context"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/pw"
+)
+
+// Seal a message with the active password in a keyring according to the options in the seal options.
+//
+// Returns a string that can be unsealed with UnsealWithKeyring for as long as the keyring holds the password.
+func SealWithKeyring[T any](message T, ring pw.Keyring, cfg SealConfig) (string, error) {
+	return SealWithKeyringContext(context.Background(), message, ring, cfg)
+}
+
+// Seal a message with the active password in a keyring, stopping with the context's error if it is done before the
+// key derivation, encryption or HMAC steps finish.
+func SealWithKeyringContext[T any](ctx context.Context, message T, ring pw.Keyring, cfg SealConfig) (string, error) {
+	messageStr, codecName, err := encodePayload(issueClaims(message, cfg), codec.Or(cfg.Codec))
+	if err != nil {
+		return "", err
+	}
+
+	pass, err := ring.Seal(time.UnixMilli(cfg.now()))
+	if err != nil {
+		return "", err
+	}
+
+	return seal(ctx, messageStr, codecName, pass, cfg)
+}
+
+// Unseal a sealed value into an object of the supplied generic type, using the password in the keyring with the
+// seal's password ID.
+//
+// Seals made with a key that is past its not-after time are rejected.
+func UnsealWithKeyring[T any](sealed string, ring pw.Keyring, cfg SealConfig) (T, error) {
+	return UnsealWithKeyringContext[T](context.Background(), sealed, ring, cfg)
+}
+
+// Unseal a sealed value with the password in the keyring with the seal's password ID, stopping with the context's
+// error if it is done before the key derivation, HMAC or decryption steps finish.
+func UnsealWithKeyringContext[T any](ctx context.Context, sealed string, ring pw.Keyring, cfg SealConfig) (T, error) {
+	return unseal[T](ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		return ring.Lookup(passwordId, time.UnixMilli(cfg.now()))
+	}, cfg)
+}
+
+// Whether a seal was made with a retired password in the keyring, so it should be resealed.
+//
+// The seal is not verified, so only use this on seals that have been unsealed.
+func SealedWithRetiredKey(sealed string, ring pw.Keyring) (bool, error) {
+	sb, err := parseUnverified(sealed)
+	if err != nil {
+		return false, err
+	}
+
+	return ring.IsRetired(sb.Id), nil
+}
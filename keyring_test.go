@@ -0,0 +1,168 @@
+package iron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func newKeyring(active string, notAfter time.Time) pw.Keyring {
+	return pw.Keyring{
+		Active: active,
+		Keys: map[string]pw.RingKey{
+			"current": {
+				Password: pw.Raw{Password: pw.Password{String: DecryptedPassword}},
+			},
+			"previous": {
+				Password: pw.Raw{Password: pw.Password{String: DecryptedPassword + "previous"}},
+				NotAfter: notAfter,
+			},
+		},
+	}
+}
+
+func TestKeyringWorks(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Clock:      fake,
+	}
+
+	// seal with the previous key while it is active, then rotate to the current key
+	oldRing := newKeyring("previous", time.Time{})
+	newRing := newKeyring("current", ttlNow.Add(time.Hour))
+
+	oldSealed, err := iron.SealWithKeyring(DecryptedMessage, oldRing, cfg)
+	a.Equals(t, err, nil)
+
+	newSealed, err := iron.SealWithKeyring(DecryptedMessage, newRing, cfg)
+	a.Equals(t, err, nil)
+
+	for _, sealed := range []string{oldSealed, newSealed} {
+		obj, err := iron.UnsealWithKeyring[string](sealed, newRing, cfg)
+		a.Equals(t, err, nil)
+		a.Equals(t, obj, DecryptedMessage)
+	}
+
+	retired, err := iron.SealedWithRetiredKey(oldSealed, newRing)
+	a.Equals(t, err, nil)
+	a.Equals(t, retired, true)
+
+	retired, err = iron.SealedWithRetiredKey(newSealed, newRing)
+	a.Equals(t, err, nil)
+	a.Equals(t, retired, false)
+
+	// the previous key is no longer accepted after its not-after time
+	fake.Advance(time.Hour + time.Millisecond)
+
+	_, err = iron.UnsealWithKeyring[string](oldSealed, newRing, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrKeyExpired), true)
+
+	obj, err := iron.UnsealWithKeyring[string](newSealed, newRing, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestKeyringUsesLocalTimeOffset(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Clock:      fake,
+	}
+
+	sealed, err := iron.SealWithKeyring(DecryptedMessage, newKeyring("previous", time.Time{}), cfg)
+	a.Equals(t, err, nil)
+
+	ring := newKeyring("current", ttlNow.Add(time.Hour))
+
+	obj, err := iron.UnsealWithKeyring[string](sealed, ring, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// the offset moves the local time past the previous key's not-after time
+	cfg.LocalTimeOffsetMsec = int(time.Hour.Milliseconds()) + 1
+
+	_, err = iron.UnsealWithKeyring[string](sealed, ring, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrKeyExpired), true)
+}
+
+func TestKeyringWorksWithUnsealRaw(t *testing.T) {
+	t.Parallel()
+
+	ring := newKeyring("current", time.Time{})
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+
+	raw, err := ring.Raw()
+	a.Equals(t, err, nil)
+
+	sealed, err := iron.Seal(DecryptedMessage, raw, cfg)
+	a.Equals(t, err, nil)
+
+	unsealRaw, err := ring.UnsealRaw()
+	a.Equals(t, err, nil)
+
+	obj, err := iron.Unseal[string](sealed, unsealRaw, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestSealWithKeyringFailsAfterActiveKeyExpires(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		Clock:      fake,
+	}
+	ring := newKeyring("previous", ttlNow.Add(time.Hour))
+
+	_, err := iron.SealWithKeyring(DecryptedMessage, ring, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Hour + time.Millisecond)
+
+	_, err = iron.SealWithKeyring(DecryptedMessage, ring, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrKeyExpired), true)
+}
+
+func TestSealedWithRetiredKeyIgnoresUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "unknown",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+
+	retired, err := iron.SealedWithRetiredKey(sealed, newKeyring("current", time.Time{}))
+	a.Equals(t, err, nil)
+	a.Equals(t, retired, false)
+}
+
+func TestSealedWithRetiredKeyFailsWithInvalidSeal(t *testing.T) {
+	t.Parallel()
+
+	_, err := iron.SealedWithRetiredKey("invalid", newKeyring("current", time.Time{}))
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
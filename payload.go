@@ -0,0 +1,93 @@
+package iron
+
+import (
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+// Marshal a message with the codec, returning the payload and the codec name to record in the seal.
+func encodePayload(message any, c codec.Codec) (string, string, error) {
+	if err := codec.ValidateName(c.Name()); err != nil {
+		return "", "", err
+	}
+
+	b, err := c.Marshal(message)
+	if err != nil {
+		return "", "", ironerrors.ErrMarshallingObject
+	}
+
+	// JSON is not recorded, so the seal can still be unsealed by @hapi/iron
+	if c.Name() == codec.JSON.Name() {
+		return str.FromBuffer(b), "", nil
+	}
+
+	return str.FromBuffer(b), c.Name(), nil
+}
+
+// Create a decoder that unmarshals payloads with the codec.
+func decodePayload[T any](c codec.Codec) payloadDecoder[T] {
+	return func(payload string) (T, error) {
+		var obj T
+
+		if err := c.Unmarshal(str.ToBuffer(payload), &obj); err != nil {
+			return obj, ironerrors.ErrUnmarshallingObject
+		}
+
+		return obj, nil
+	}
+}
+
+// Compress a payload with the configured compressor, returning the payload and the compressor name to record in the
+// seal.
+//
+// Payloads below the threshold, or that compression does not make smaller, are returned as they are.
+func compressPayload(payload string, cfg SealConfig) (string, string, error) {
+	if cfg.Compressor == nil || len(payload) < cfg.CompressThreshold {
+		return payload, "", nil
+	}
+
+	if err := compress.ValidateName(cfg.Compressor.Name()); err != nil {
+		return "", "", err
+	}
+
+	compressed, err := cfg.Compressor.Compress(str.ToBuffer(payload))
+	if err != nil {
+		return "", "", ironerrors.ErrCompressing
+	}
+	if len(compressed) >= len(payload) {
+		return payload, "", nil
+	}
+
+	return str.FromBuffer(compressed), cfg.Compressor.Name(), nil
+}
+
+// Wrap a decoder so it decompresses payloads with the named compressor first.
+//
+// Returns an error if the compressor is neither configured nor registered.
+func decompressPayload[T any](decode payloadDecoder[T], compression string, cfg SealConfig) (payloadDecoder[T], error) {
+	if compression == "" {
+		return decode, nil
+	}
+
+	c := cfg.Compressor
+	if c == nil || c.Name() != compression {
+		found, ok := compress.Lookup(compression)
+		if !ok {
+			return nil, ironerrors.ErrUnsupportedCompression
+		}
+		c = found
+	}
+
+	return func(payload string) (T, error) {
+		var obj T
+
+		decompressed, err := compress.Decompress(c, str.ToBuffer(payload), cfg.MaxDecompressedSize)
+		if err != nil {
+			return obj, err
+		}
+
+		return decode(str.FromBuffer(decompressed))
+	}, nil
+}
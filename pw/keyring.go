@@ -0,0 +1,120 @@
+package pw
+
+import (
+	"time"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+)
+
+// A password in a keyring.
+type RingKey struct {
+	// The password. Its ID is replaced by the key's ID in the keyring.
+	Password Raw
+	// Time after which seals made with the key are no longer unsealed.
+	//
+	// The zero time means they are always unsealed.
+	NotAfter time.Time
+}
+
+// A set of passwords for key rotation, with one active password for sealing and retired passwords that are only
+// used for unsealing.
+type Keyring struct {
+	// ID of the key to seal with.
+	Active string
+	// Keys by ID, including the active key. IDs must only contain letters.
+	Keys map[string]RingKey
+}
+
+// Normalise the key with the ID, using the ID as the password ID.
+func (ring Keyring) normalise(id string) (Specific, RingKey, error) {
+	k, ok := ring.Keys[id]
+	if !ok {
+		return Specific{}, RingKey{}, passwordNotFound(id)
+	}
+
+	normalised, err := normalisePassword(k.Password)
+	if err != nil {
+		return Specific{}, RingKey{}, err
+	}
+
+	normalised.Id = id
+	if err = validatePassword(normalised); err != nil {
+		return Specific{}, RingKey{}, err
+	}
+
+	return normalised, k, nil
+}
+
+// Retrieve the active password to seal with.
+//
+// Returns an error if the active key's not-after time is before now, as its seals could not be unsealed.
+func (ring Keyring) Seal(now time.Time) (Specific, error) {
+	return ring.lookup(ring.Active, now)
+}
+
+// Look up the password to unseal with for the password ID in a seal.
+//
+// Returns an error if there is no key with the ID, or if the key's not-after time is before now.
+func (ring Keyring) Lookup(passwordId string, now time.Time) (Specific, error) {
+	return ring.lookup(passwordId, now)
+}
+
+// normalise the key with the ID, checking its not-after time
+func (ring Keyring) lookup(id string, now time.Time) (Specific, error) {
+	normalised, k, err := ring.normalise(id)
+	if err != nil {
+		return Specific{}, err
+	}
+
+	if !k.NotAfter.IsZero() && now.After(k.NotAfter) {
+		return Specific{}, &ironerrors.SealError{
+			Reason:     ironerrors.ReasonPasswordExpired,
+			Field:      ironerrors.FieldPasswordId,
+			PasswordId: id,
+			Expiration: k.NotAfter,
+			Err:        ironerrors.ErrKeyExpired,
+		}
+	}
+
+	return normalised, nil
+}
+
+// Whether the password ID belongs to a key in the keyring other than the active key, so seals made with it should be
+// resealed.
+//
+// Returns false for IDs that are not in the keyring, as their seals cannot be unsealed to reseal them.
+func (ring Keyring) IsRetired(passwordId string) bool {
+	_, ok := ring.Keys[passwordId]
+
+	return ok && passwordId != ring.Active
+}
+
+// Convert the keyring to a password that seals with the active key.
+//
+// The not-after time of the active key is not enforced by the password.
+func (ring Keyring) Raw() (Raw, error) {
+	normalised, _, err := ring.normalise(ring.Active)
+	if err != nil {
+		return Raw{}, err
+	}
+
+	return Raw{Specific: normalised}, nil
+}
+
+// Convert the keyring to an unseal password with every key.
+//
+// The not-after times of the keys are not enforced by the unseal password.
+func (ring Keyring) UnsealRaw() (UnsealRaw, error) {
+	raw := UnsealRaw{Map: make(map[string]Raw, len(ring.Keys))}
+
+	for id := range ring.Keys {
+		normalised, _, err := ring.normalise(id)
+		if err != nil {
+			return UnsealRaw{}, err
+		}
+
+		raw.Map[id] = Raw{Specific: normalised}
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,119 @@
+package pw_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+var (
+	notAfter = time.UnixMilli(1700000000000)
+	keyring  = pw.Keyring{
+		Active: "current",
+		Keys: map[string]pw.RingKey{
+			"current": {
+				Password: pw.Raw{Password: pw.Password{String: "current password"}},
+			},
+			"previous": {
+				Password: pw.Raw{Secret: pw.Secret{Id: "ignored", Secret: pw.Password{String: "previous password"}}},
+				NotAfter: notAfter,
+			},
+		},
+	}
+)
+
+func TestKeyringSealUsesActiveKey(t *testing.T) {
+	t.Parallel()
+
+	pass, err := keyring.Seal(notAfter)
+	a.Equals(t, err, nil)
+	a.Equals(t, pass.Id, "current")
+	a.Equals(t, pass.Encryption.String, "current password")
+	a.Equals(t, pass.Integrity.String, "current password")
+
+	raw, err := keyring.Raw()
+	a.Equals(t, err, nil)
+
+	normalised, err := pw.Normalise(raw)
+	a.Equals(t, err, nil)
+	a.Equals(t, normalised.Id, "current")
+}
+
+func TestKeyringSealFailsAfterActiveKeyExpires(t *testing.T) {
+	t.Parallel()
+
+	ring := pw.Keyring{Active: "previous", Keys: keyring.Keys}
+
+	pass, err := ring.Seal(notAfter)
+	a.Equals(t, err, nil)
+	a.Equals(t, pass.Id, "previous")
+
+	_, err = ring.Seal(notAfter.Add(time.Millisecond))
+	a.Equals(t, errors.Is(err, ironerrors.ErrKeyExpired), true)
+}
+
+func TestKeyringLookup(t *testing.T) {
+	t.Parallel()
+
+	pass, err := keyring.Lookup("previous", notAfter)
+	a.Equals(t, err, nil)
+	a.Equals(t, pass.Id, "previous")
+	a.Equals(t, pass.Encryption.String, "previous password")
+
+	_, err = keyring.Lookup("previous", notAfter.Add(time.Millisecond))
+	a.Equals(t, errors.Is(err, ironerrors.ErrKeyExpired), true)
+
+	pass, err = keyring.Lookup("current", notAfter.Add(time.Hour))
+	a.Equals(t, err, nil)
+	a.Equals(t, pass.Id, "current")
+
+	_, err = keyring.Lookup("unknown", notAfter)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
+
+func TestKeyringIsRetired(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, keyring.IsRetired("current"), false)
+	a.Equals(t, keyring.IsRetired("previous"), true)
+	a.Equals(t, keyring.IsRetired("unknown"), false)
+}
+
+func TestKeyringUnsealRaw(t *testing.T) {
+	t.Parallel()
+
+	raw, err := keyring.UnsealRaw()
+	a.Equals(t, err, nil)
+
+	pass, err := pw.NormaliseUnseal(raw, "previous")
+	a.Equals(t, err, nil)
+	a.Equals(t, pass.Id, "previous")
+	a.Equals(t, pass.Encryption.String, "previous password")
+}
+
+func TestKeyringFailsWithInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	_, err := pw.Keyring{Active: "missing"}.Seal(notAfter)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+
+	_, err = pw.Keyring{
+		Active: "key1",
+		Keys: map[string]pw.RingKey{
+			"key1": {Password: pw.Raw{Password: pw.Password{String: "password"}}},
+		},
+	}.Seal(notAfter)
+	a.EqualsError(t, err, ironerrors.ErrPasswordInvalid)
+
+	_, err = pw.Keyring{
+		Active: "current",
+		Keys: map[string]pw.RingKey{
+			"current": {},
+		},
+	}.UnsealRaw()
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
@@ -14,6 +14,9 @@ type Password struct {
 	String string
 	// A byte buffer to use for the password.
 	Buffer []byte
+	// Treat the byte buffer as a master key and derive distinct encryption and integrity subkeys from it with HKDF,
+	// using a new salt for every seal, instead of using the buffer as the key directly.
+	HKDF bool
 }
 
 // A password with an ID.
@@ -99,6 +102,16 @@ func validatePassword(raw Specific) error {
 	return nil
 }
 
+// Create an error for a password ID without a password.
+func passwordNotFound(passwordId string) error {
+	return &ironerrors.SealError{
+		Reason:     ironerrors.ReasonPasswordNotFound,
+		Field:      ironerrors.FieldPasswordId,
+		PasswordId: passwordId,
+		Err:        ironerrors.ErrPasswordRequired,
+	}
+}
+
 // Normalise a password.
 func Normalise(raw Raw) (Specific, error) {
 	normalised, err := normalisePassword(raw)
@@ -128,7 +141,7 @@ func NormaliseUnseal(raw UnsealRaw, passwordId string) (Specific, error) {
 		if !ok {
 			foundPassword, ok = raw.Map["default"]
 			if !ok {
-				return Specific{}, ironerrors.ErrPasswordRequired
+				return Specific{}, passwordNotFound(passwordId)
 			}
 		}
 	} else {
@@ -144,3 +157,53 @@ func NormaliseUnseal(raw UnsealRaw, passwordId string) (Specific, error) {
 
 	return normalised, nil
 }
+
+// Unseal passwords normalised ahead of time, to look up by password ID.
+type UnsealSet struct {
+	password  Specific
+	passwords map[string]Specific
+}
+
+// Normalise every password in an unseal password.
+func NormaliseUnsealSet(raw UnsealRaw) (UnsealSet, error) {
+	if raw.Password.String == "" && len(raw.Password.Buffer) == 0 && len(raw.Map) == 0 {
+		return UnsealSet{}, ironerrors.ErrPasswordRequired
+	}
+
+	if len(raw.Map) == 0 {
+		normalised, err := Normalise(Raw{Password: raw.Password})
+		if err != nil {
+			return UnsealSet{}, err
+		}
+
+		return UnsealSet{password: normalised}, nil
+	}
+
+	set := UnsealSet{passwords: make(map[string]Specific, len(raw.Map))}
+	for id, password := range raw.Map {
+		normalised, err := Normalise(password)
+		if err != nil {
+			return UnsealSet{}, err
+		}
+
+		set.passwords[id] = normalised
+	}
+
+	return set, nil
+}
+
+// Look up the password for a password ID, in the same way as NormaliseUnseal.
+func (set UnsealSet) Lookup(passwordId string) (Specific, error) {
+	if set.passwords == nil {
+		return set.password, nil
+	}
+
+	if password, ok := set.passwords[passwordId]; ok {
+		return password, nil
+	}
+	if password, ok := set.passwords["default"]; ok {
+		return password, nil
+	}
+
+	return Specific{}, passwordNotFound(passwordId)
+}
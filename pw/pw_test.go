@@ -1,6 +1,7 @@
 package pw_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/iron-auth/iron-crypto/ironerrors"
@@ -13,7 +14,7 @@ func TestMissingPasswordReturnsError(t *testing.T) {
 
 	_, err := pw.Normalise(pw.Raw{})
 
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestInvalidPasswordReturnsError(t *testing.T) {
@@ -24,7 +25,7 @@ func TestInvalidPasswordReturnsError(t *testing.T) {
 			String: "",
 		}})
 
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 
 	_, err = pw.Normalise(pw.Raw{
 		Secret: pw.Secret{
@@ -86,7 +87,7 @@ func TestNormaliseUnsealBlankPassword(t *testing.T) {
 		},
 	}, "test")
 
-	a.EqualsError(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestNormaliseUnsealString(t *testing.T) {
@@ -158,7 +159,7 @@ func TestNormaliseUnsealListFindsNone(t *testing.T) {
 		},
 	}, "testid")
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestNormaliseUnsealListFindsButFindsInvalidPassword(t *testing.T) {
@@ -198,3 +199,68 @@ func TestNormaliseUnsealListFindsValidSecret(t *testing.T) {
 
 	a.Equals(t, err, nil)
 }
+
+func TestNormaliseUnsealSetBlankPassword(t *testing.T) {
+	t.Parallel()
+
+	_, err := pw.NormaliseUnsealSet(pw.UnsealRaw{})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
+
+func TestNormaliseUnsealSetFailsOnInvalidPassword(t *testing.T) {
+	t.Parallel()
+
+	_, err := pw.NormaliseUnsealSet(pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"valid": {
+				Password: pw.Password{String: "password"},
+			},
+			"invalid": {},
+		},
+	})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
+
+func TestNormaliseUnsealSetLookup(t *testing.T) {
+	t.Parallel()
+
+	set, err := pw.NormaliseUnsealSet(pw.UnsealRaw{
+		Password: pw.Password{String: "password"},
+	})
+	a.Equals(t, err, nil)
+
+	found, err := set.Lookup("anything")
+	a.Equals(t, err, nil)
+	a.Equals(t, found.Encryption.String, "password")
+
+	set, err = pw.NormaliseUnsealSet(pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"one": {
+				Password: pw.Password{String: "password"},
+			},
+		},
+	})
+	a.Equals(t, err, nil)
+
+	found, err = set.Lookup("one")
+	a.Equals(t, err, nil)
+	a.Equals(t, found.Integrity.String, "password")
+
+	_, err = set.Lookup("two")
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+
+	set, err = pw.NormaliseUnsealSet(pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"default": {
+				Password: pw.Password{String: "fallback"},
+			},
+		},
+	})
+	a.Equals(t, err, nil)
+
+	found, err = set.Lookup("two")
+	a.Equals(t, err, nil)
+	a.Equals(t, found.Encryption.String, "fallback")
+}
@@ -0,0 +1,121 @@
+package iron
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/encryption"
+	"github.com/iron-auth/iron-crypto/pw"
+)
+
+// Reseal a sealed value that was sealed with an older password or seal options, so it uses the current ones.
+//
+// The value is unsealed into an object of the supplied generic type with the unseal password and the old seal
+// options, then sealed with the password and the new seal options. The new seal keeps the original expiration
+// instead of starting a new TTL.
+//
+// Returns the sealed value as it was, and false, when the password and seal options have not changed.
+func Reseal[T any](sealed string, unsealPassword pw.UnsealRaw, from SealConfig, password pw.Raw, to SealConfig) (string, bool, error) {
+	return ResealContext[T](context.Background(), sealed, unsealPassword, from, password, to)
+}
+
+// Reseal a sealed value that was sealed with an older password or seal options, stopping with the context's error if
+// it is done before the unsealing or sealing steps finish.
+func ResealContext[T any](ctx context.Context, sealed string, unsealPassword pw.UnsealRaw, from SealConfig, password pw.Raw, to SealConfig) (string, bool, error) {
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return "", false, err
+	}
+
+	var used pw.Specific
+	obj, err := unseal[T](ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		found, err := pw.NormaliseUnseal(unsealPassword, passwordId)
+		used = found
+
+		return found, err
+	}, from)
+	if err != nil {
+		return "", false, err
+	}
+
+	// the seal has already been verified by unsealing it
+	sb, err := parseUnverified(sealed)
+	if err != nil {
+		return "", false, err
+	}
+
+	messageStr, codecName, err := encodePayload(obj, codec.Or(to.Codec))
+	if err != nil {
+		return "", false, err
+	}
+
+	if samePassword(used, pass) && sb.Id == pass.Id && sameSealOptions(from, to) && sealedWith(sb.Params, messageStr, codecName, to) {
+		return sealed, false, nil
+	}
+
+	resealed, err := sealWithExpiration(ctx, messageStr, codecName, pass, sb.Expiration, to)
+	if err != nil {
+		return "", false, err
+	}
+
+	return resealed, true, nil
+}
+
+// Parse a seal without checking its expiration or verifying it.
+func parseUnverified(sealed string) (encryption.SealBuilder, error) {
+	sb := encryption.SealBuilder{}
+	err := sb.Decode(sealed)
+
+	return sb, err
+}
+
+// check whether two normalised passwords are the same
+func samePassword(a pw.Specific, b pw.Specific) bool {
+	equal := func(x pw.Password, y pw.Password) bool {
+		return x.String == y.String && bytes.Equal(x.Buffer, y.Buffer) && x.HKDF == y.HKDF
+	}
+
+	return a.Id == b.Id && equal(a.Encryption, b.Encryption) && equal(a.Integrity, b.Integrity)
+}
+
+// check whether two seal configs produce the same kind of seal
+func sameSealOptions(a SealConfig, b SealConfig) bool {
+	// the minimum password length and accepted KDFs do not change the seal
+	sealOptions := func(options SealConfigOptions) SealConfigOptions {
+		options.MinPasswordLength = 0
+		options.AcceptedKDFs = 0
+		return options
+	}
+	compressor := func(cfg SealConfig) string {
+		if cfg.Compressor == nil {
+			return ""
+		}
+		return cfg.Compressor.Name()
+	}
+
+	if sealOptions(a.Encryption) != sealOptions(b.Encryption) {
+		return false
+	}
+	// AEAD seals do not use the integrity options
+	if !b.Encryption.Algorithm.IsAEAD() && sealOptions(a.Integrity) != sealOptions(b.Integrity) {
+		return false
+	}
+
+	return codec.Or(a.Codec).Name() == codec.Or(b.Codec).Name() && compressor(a) == compressor(b)
+}
+
+// check whether a seal's recorded parameters are the ones the config would use for the encoded payload
+func sealedWith(params encryption.SealParams, messageStr string, codecName string, cfg SealConfig) bool {
+	if params.AEAD != cfg.Encryption.Algorithm.IsAEAD() || params.KDF != cfg.Encryption.KDF || params.Codec != codecName {
+		return false
+	}
+	// AEAD seals do not have an integrity key
+	if !params.AEAD && params.IntegrityKDF != cfg.Integrity.KDF {
+		return false
+	}
+
+	_, compression, err := compressPayload(messageStr, cfg)
+
+	return err == nil && params.Compression == compression
+}
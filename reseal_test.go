@@ -0,0 +1,248 @@
+package iron_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+var (
+	resealPassword = pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}
+	resealUnsealPassword = pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}
+)
+
+func TestResealKeepsUnchangedSeal(t *testing.T) {
+	t.Parallel()
+
+	from := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+	to := from
+	to.Encryption.MinPasswordLength = 64
+
+	sealed, err := iron.Seal(DecryptedMessage, resealPassword, from)
+	a.Equals(t, err, nil)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, from, resealPassword, to)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, false)
+	a.Equals(t, resealed, sealed)
+}
+
+func TestResealUpgradesOptionsAndKeepsExpiration(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	from := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		TTL:        60000,
+		Clock:      fake,
+	}
+	aead := iron.DefaultEncryption
+	aead.Algorithm = key.AES256GCM
+	aead.Iterations = 10
+	to := iron.SealConfig{
+		Encryption: aead,
+		Integrity:  iron.DefaultIntegrity,
+		TTL:        1000,
+		Clock:      fake,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, resealPassword, from)
+	a.Equals(t, err, nil)
+
+	fake.Advance(30 * time.Second)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, from, resealPassword, to)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[0], "Fe26.2~aead")
+	a.Equals(t, strings.Split(resealed, "*")[5], strings.Split(sealed, "*")[5])
+
+	obj, err := iron.Unseal[string](resealed, resealUnsealPassword, to)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	_, err = iron.Unseal[string](resealed, resealUnsealPassword, from)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
+
+func TestResealUpgradesPassword(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+	oldPassword := pw.Raw{
+		Secret: pw.Secret{
+			Id:     "old",
+			Secret: pw.Password{String: DecryptedPassword + "old"},
+		},
+	}
+	newPassword := pw.Raw{
+		Secret: pw.Secret{
+			Id:     "new",
+			Secret: pw.Password{String: DecryptedPassword + "new"},
+		},
+	}
+	unsealPassword := pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"old": oldPassword,
+			"new": newPassword,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, oldPassword, cfg)
+	a.Equals(t, err, nil)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, unsealPassword, cfg, newPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[1], "new")
+
+	obj, err := iron.Unseal[string](resealed, unsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// a seal that already uses the new password is kept
+	again, upgraded, err := iron.Reseal[string](resealed, unsealPassword, cfg, newPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, false)
+	a.Equals(t, again, resealed)
+}
+
+func TestResealUpgradesCodec(t *testing.T) {
+	t.Parallel()
+
+	from := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+	to := from
+	to.Codec = codec.CBOR
+
+	sealed, err := iron.Seal(DecryptedMessage, resealPassword, from)
+	a.Equals(t, err, nil)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, from, resealPassword, to)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[0], "Fe26.2~codec=cbor")
+
+	obj, err := iron.Unseal[string](resealed, resealUnsealPassword, to)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestResealComparesSealWithNewOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+	message := strings.Repeat(DecryptedMessage, 100)
+
+	sealed, err := iron.Seal(message, resealPassword, cfg)
+	a.Equals(t, err, nil)
+
+	// the seal was not compressed, even though the old and new options now are
+	cfg.Compressor = compress.Deflate
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, cfg, resealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[0], "Fe26.2~zip=deflate")
+
+	again, upgraded, err := iron.Reseal[string](resealed, resealUnsealPassword, cfg, resealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, false)
+	a.Equals(t, again, resealed)
+}
+
+func TestResealUpgradesAcceptedKdf(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := iron.Seal(DecryptedMessage, resealPassword, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+	cfg.Encryption.KDF = key.Argon2id
+	cfg.Encryption.Argon2 = key.Argon2Params{Memory: 1024, Time: 1, Parallelism: 1}
+	cfg.Encryption.AcceptedKDFs = key.KDFs(key.PBKDF2SHA1)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, cfg, resealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[0], "Fe26.2~kdf=argon2id")
+
+	again, upgraded, err := iron.Reseal[string](resealed, resealUnsealPassword, cfg, resealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, false)
+	a.Equals(t, again, resealed)
+}
+
+func TestResealComparesSealWithNewPasswordId(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "first",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	resealed, upgraded, err := iron.Reseal[string](sealed, resealUnsealPassword, cfg, resealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, upgraded, true)
+	a.Equals(t, strings.Split(resealed, "*")[1], "")
+}
+
+func TestResealFailsWithInvalidSeal(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	_, _, err := iron.Reseal[string](SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword + "wrong",
+		},
+	}, cfg, resealPassword, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
+}
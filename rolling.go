@@ -0,0 +1,210 @@
+package iron
+
+import (
+	"context"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/james-elicx/go-utils/utils"
+)
+
+// Default fraction of the TTL below which a rolling seal is resealed.
+const DefaultRefreshFraction = 0.5
+
+// Options for rolling seals, that are resealed with a fresh expiration while they are in use.
+type RollingConfig struct {
+	// Reseal when less than this fraction of the TTL remains. Defaults to DefaultRefreshFraction.
+	//
+	// A fraction of 1 or more reseals every time.
+	RefreshFraction float64
+	// Maximum age of a rolling seal from when it was first sealed, regardless of how often it is resealed. The
+	// expiration is never moved past it. Zero for no maximum age.
+	MaxAge time.Duration
+}
+
+func (rolling RollingConfig) refreshFraction() float64 {
+	if rolling.RefreshFraction <= 0 {
+		return DefaultRefreshFraction
+	}
+
+	return rolling.RefreshFraction
+}
+
+// Latest expiration in milliseconds for a rolling seal first sealed at the created time, or zero if there is none.
+func (rolling RollingConfig) maxExpiration(created int64) int64 {
+	if rolling.MaxAge <= 0 {
+		return 0
+	}
+
+	return created + rolling.MaxAge.Milliseconds()
+}
+
+// Payload of a rolling seal, with the time the message was first sealed.
+type RollingPayload[T any] struct {
+	// The message.
+	Data T `json:"data"`
+	// Time the message was first sealed, in milliseconds since the Unix epoch.
+	//
+	// Set to the current time when sealing if it is zero.
+	Created int64 `json:"created"`
+}
+
+// Unseal a sealed value into an object of the supplied generic type, also returning how long remains until the seal
+// expires.
+//
+// The remaining lifetime is zero if the seal does not expire.
+func UnsealWithRemaining[T any](sealed string, password pw.UnsealRaw, cfg SealConfig) (T, time.Duration, error) {
+	return UnsealWithRemainingContext[T](context.Background(), sealed, password, cfg)
+}
+
+// Unseal a sealed value into an object of the supplied generic type, also returning how long remains until the seal
+// expires, stopping with the context's error if it is done before the key derivation, HMAC or decryption steps
+// finish.
+func UnsealWithRemainingContext[T any](ctx context.Context, sealed string, password pw.UnsealRaw, cfg SealConfig) (T, time.Duration, error) {
+	return unsealWithRemaining[T](ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		return pw.NormaliseUnseal(password, passwordId)
+	}, cfg)
+}
+
+// Seal a message as a rolling seal, that expires after the TTL or the maximum age, whichever is first.
+//
+// The seal must be unsealed with Roll, as the message is wrapped with the time it was first sealed.
+func SealRolling[T any](payload RollingPayload[T], password pw.Raw, cfg SealConfig, rolling RollingConfig) (string, error) {
+	return SealRollingContext(context.Background(), payload, password, cfg, rolling)
+}
+
+// Seal a message as a rolling seal, stopping with the context's error if it is done before the key derivation,
+// encryption or HMAC steps finish.
+func SealRollingContext[T any](ctx context.Context, payload RollingPayload[T], password pw.Raw, cfg SealConfig, rolling RollingConfig) (string, error) {
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return "", err
+	}
+
+	return sealRolling(ctx, payload, pass, cfg, rolling)
+}
+
+// Unseal a rolling seal, resealing it with the password and a fresh expiration when less than the refresh fraction
+// of the TTL remains.
+//
+// Returns the sealed value as it was, and false, when the seal does not need to be resealed yet. Seals older than
+// the maximum age are rejected with ironerrors.ErrMaxAgeExceeded.
+func Roll[T any](sealed string, password pw.Raw, unsealPassword pw.UnsealRaw, cfg SealConfig, rolling RollingConfig) (RollingPayload[T], string, bool, error) {
+	return RollContext[T](context.Background(), sealed, password, unsealPassword, cfg, rolling)
+}
+
+// Unseal a rolling seal, resealing it when less than the refresh fraction of the TTL remains, stopping with the
+// context's error if it is done before the unsealing or sealing steps finish.
+func RollContext[T any](ctx context.Context, sealed string, password pw.Raw, unsealPassword pw.UnsealRaw, cfg SealConfig, rolling RollingConfig) (RollingPayload[T], string, bool, error) {
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return RollingPayload[T]{}, "", false, err
+	}
+
+	return roll[T](ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		return pw.NormaliseUnseal(unsealPassword, passwordId)
+	}, pass, cfg, rolling)
+}
+
+// Unseal a sealed value with the password found by the lookup, also returning how long remains until it expires.
+func unsealWithRemaining[T any](ctx context.Context, sealed string, lookup passwordLookup, cfg SealConfig) (T, time.Duration, error) {
+	obj, err := unseal[T](ctx, sealed, lookup, cfg)
+	if err != nil {
+		return obj, 0, err
+	}
+
+	// the seal has already been verified by unsealing it
+	sb, err := parseUnverified(sealed)
+	if err != nil || sb.Expiration == 0 {
+		return obj, 0, err
+	}
+
+	return obj, time.Duration(sb.Expiration-cfg.now()) * time.Millisecond, nil
+}
+
+// Seal a rolling payload with a normalised password.
+func sealRolling[T any](ctx context.Context, payload RollingPayload[T], pass pw.Specific, cfg SealConfig, rolling RollingConfig) (string, error) {
+	now := cfg.now()
+	if payload.Created == 0 {
+		payload.Created = now
+	}
+
+	maxExpiration := rolling.maxExpiration(payload.Created)
+	if maxExpiration != 0 && now >= maxExpiration {
+		return "", maxAgeExceeded(maxExpiration)
+	}
+
+	ttl := cfg.ttl()
+
+	return sealRollingPayload(ctx, payload, pass, capExpiration(utils.Ternary(ttl > 0, now+ttl, 0), maxExpiration), cfg)
+}
+
+// Unseal a rolling seal with the password found by the lookup, resealing it with the normalised password when it
+// needs a fresh expiration.
+func roll[T any](ctx context.Context, sealed string, lookup passwordLookup, pass pw.Specific, cfg SealConfig, rolling RollingConfig) (RollingPayload[T], string, bool, error) {
+	payload, err := unseal[RollingPayload[T]](ctx, sealed, lookup, cfg)
+	if err != nil {
+		return payload, "", false, err
+	}
+
+	// the seal has already been verified by unsealing it
+	sb, err := parseUnverified(sealed)
+	if err != nil {
+		return payload, "", false, err
+	}
+
+	now := cfg.now()
+
+	maxExpiration := rolling.maxExpiration(payload.Created)
+	if maxExpiration != 0 && now >= maxExpiration {
+		return payload, "", false, maxAgeExceeded(maxExpiration)
+	}
+
+	ttl := cfg.ttl()
+	if ttl == 0 || sb.Expiration == 0 || float64(sb.Expiration-now) >= rolling.refreshFraction()*float64(ttl) {
+		return payload, sealed, false, nil
+	}
+
+	expiration := capExpiration(now+ttl, maxExpiration)
+	if expiration <= sb.Expiration {
+		// the seal already expires at its maximum age
+		return payload, sealed, false, nil
+	}
+
+	resealed, err := sealRollingPayload(ctx, payload, pass, expiration, cfg)
+	if err != nil {
+		return payload, "", false, err
+	}
+
+	return payload, resealed, true, nil
+}
+
+func sealRollingPayload[T any](ctx context.Context, payload RollingPayload[T], pass pw.Specific, expiration int64, cfg SealConfig) (string, error) {
+	messageStr, codecName, err := encodePayload(payload, codec.Or(cfg.Codec))
+	if err != nil {
+		return "", err
+	}
+
+	return sealWithExpiration(ctx, messageStr, codecName, pass, expiration, cfg)
+}
+
+// limit an expiration to the maximum expiration, where zero means there is no limit
+func capExpiration(expiration int64, maxExpiration int64) int64 {
+	if maxExpiration != 0 && (expiration == 0 || expiration > maxExpiration) {
+		return maxExpiration
+	}
+
+	return expiration
+}
+
+// create an error for a rolling seal that is past its maximum age
+func maxAgeExceeded(maxExpiration int64) error {
+	return &ironerrors.SealError{
+		Reason:     ironerrors.ReasonExpired,
+		Field:      ironerrors.FieldPayload,
+		Expiration: time.UnixMilli(maxExpiration),
+		Err:        ironerrors.ErrMaxAgeExceeded,
+	}
+}
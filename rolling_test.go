@@ -0,0 +1,188 @@
+package iron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+var (
+	rollingPassword       = pw.Raw{Password: pw.Password{String: DecryptedPassword}}
+	rollingUnsealPassword = pw.UnsealRaw{Password: pw.Password{String: DecryptedPassword}}
+)
+
+func rollingConfig(fake *clocktest.Fake) iron.SealConfig {
+	return iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TTL:              60000,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+}
+
+func TestUnsealWithRemainingReportsLifetime(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := rollingConfig(fake)
+
+	sealed, err := iron.Seal(DecryptedMessage, rollingPassword, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(20 * time.Second)
+	obj, remaining, err := iron.UnsealWithRemaining[string](sealed, rollingUnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+	a.Equals(t, remaining, 40*time.Second)
+
+	cfg.TTL = 0
+	sealed, err = iron.Seal(DecryptedMessage, rollingPassword, cfg)
+	a.Equals(t, err, nil)
+
+	_, remaining, err = iron.UnsealWithRemaining[string](sealed, rollingUnsealPassword, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, remaining, time.Duration(0))
+}
+
+func TestRollResealsBelowRefreshFraction(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := rollingConfig(fake)
+	rolling := iron.RollingConfig{}
+
+	sealed, err := iron.SealRolling(iron.RollingPayload[string]{Data: DecryptedMessage}, rollingPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+
+	fake.Advance(20 * time.Second)
+	payload, same, resealed, err := iron.Roll[string](sealed, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, false)
+	a.Equals(t, same, sealed)
+	a.Equals(t, payload.Data, DecryptedMessage)
+	a.Equals(t, payload.Created, ttlNow.UnixMilli())
+
+	fake.Advance(20 * time.Second)
+	payload, rolled, resealed, err := iron.Roll[string](sealed, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, true)
+	a.Equals(t, payload.Created, ttlNow.UnixMilli())
+
+	info, err := iron.Inspect(rolled)
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Expiration.Equal(ttlNow.Add(100*time.Second)), true)
+
+	// the original seal expires, but the rolled one lives on
+	fake.Advance(30 * time.Second)
+	_, _, _, err = iron.Roll[string](sealed, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+
+	payload, _, _, err = iron.Roll[string](rolled, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, payload.Data, DecryptedMessage)
+}
+
+func TestRollWithRefreshFractionOfOneAlwaysReseals(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := rollingConfig(fake)
+	rolling := iron.RollingConfig{RefreshFraction: 1}
+
+	sealed, err := iron.SealRolling(iron.RollingPayload[string]{Data: DecryptedMessage}, rollingPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Millisecond)
+	_, _, resealed, err := iron.Roll[string](sealed, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, true)
+}
+
+func TestRollIsCappedByMaxAge(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := rollingConfig(fake)
+	rolling := iron.RollingConfig{MaxAge: 90 * time.Second}
+
+	sealed, err := iron.SealRolling(iron.RollingPayload[string]{Data: DecryptedMessage}, rollingPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+
+	fake.Advance(40 * time.Second)
+	_, rolled, resealed, err := iron.Roll[string](sealed, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, true)
+
+	info, err := iron.Inspect(rolled)
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Expiration.Equal(ttlNow.Add(90*time.Second)), true)
+
+	// the seal already expires at its maximum age, so it is not resealed
+	fake.Advance(30 * time.Second)
+	_, same, resealed, err := iron.Roll[string](rolled, rollingPassword, rollingUnsealPassword, cfg, rolling)
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, false)
+	a.Equals(t, same, rolled)
+
+	_, _, _, err = iron.Roll[string](rolled, rollingPassword, rollingUnsealPassword, cfg, iron.RollingConfig{MaxAge: time.Minute})
+	a.Equals(t, errors.Is(err, ironerrors.ErrMaxAgeExceeded), true)
+
+	var sealErr *ironerrors.SealError
+	a.Equals(t, errors.As(err, &sealErr), true)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonExpired)
+	a.Equals(t, sealErr.Expiration.Equal(ttlNow.Add(time.Minute)), true)
+}
+
+func TestSealRollingFailsPastMaxAge(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := rollingConfig(fake)
+
+	_, err := iron.SealRolling(iron.RollingPayload[string]{
+		Data:    DecryptedMessage,
+		Created: ttlNow.Add(-time.Hour).UnixMilli(),
+	}, rollingPassword, cfg, iron.RollingConfig{MaxAge: time.Hour})
+	a.Equals(t, errors.Is(err, ironerrors.ErrMaxAgeExceeded), true)
+
+	// without a TTL, the seal expires at its maximum age
+	cfg.TTL = 0
+	sealed, err := iron.SealRolling(iron.RollingPayload[string]{Data: DecryptedMessage}, rollingPassword, cfg, iron.RollingConfig{MaxAge: time.Hour})
+	a.Equals(t, err, nil)
+
+	info, err := iron.Inspect(sealed)
+	a.Equals(t, err, nil)
+	a.Equals(t, info.Expiration.Equal(ttlNow.Add(time.Hour)), true)
+}
+
+func TestSealerRolls(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	sealer, err := iron.NewSealer[string](rollingPassword, pw.UnsealRaw{}, rollingConfig(fake))
+	a.Equals(t, err, nil)
+
+	sealed, err := sealer.SealRolling(iron.RollingPayload[string]{Data: DecryptedMessage}, iron.RollingConfig{})
+	a.Equals(t, err, nil)
+
+	fake.Advance(45 * time.Second)
+	payload, rolled, resealed, err := sealer.Roll(sealed, iron.RollingConfig{})
+	a.Equals(t, err, nil)
+	a.Equals(t, resealed, true)
+	a.Equals(t, payload.Data, DecryptedMessage)
+
+	payloads, err := iron.NewSealer[iron.RollingPayload[string]](rollingPassword, pw.UnsealRaw{}, rollingConfig(fake))
+	a.Equals(t, err, nil)
+
+	payload, remaining, err := payloads.UnsealWithRemaining(rolled)
+	a.Equals(t, err, nil)
+	a.Equals(t, payload.Data, DecryptedMessage)
+	a.Equals(t, remaining, time.Minute)
+}
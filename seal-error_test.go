@@ -0,0 +1,223 @@
+package iron_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func asSealError(t *testing.T, err error) *ironerrors.SealError {
+	var sealErr *ironerrors.SealError
+	a.Equals(t, errors.As(err, &sealErr), true)
+
+	return sealErr
+}
+
+func TestSealErrorForExpiredSeal(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       SealEncryption,
+		Integrity:        SealIntegrity,
+		TTL:              60000,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "first",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(2 * time.Minute)
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"first": {Password: pw.Password{String: DecryptedPassword}},
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonExpired)
+	a.Equals(t, sealErr.Field, ironerrors.FieldExpiration)
+	a.Equals(t, sealErr.PasswordId, "first")
+	a.Equals(t, sealErr.Expiration.Equal(ttlNow.Add(time.Minute)), true)
+}
+
+func TestSealErrorForMissingPasswordId(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		TTL:        60000,
+		Clock:      fake,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "first",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"second": {Password: pw.Password{String: DecryptedPassword}},
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonPasswordNotFound)
+	a.Equals(t, sealErr.Field, ironerrors.FieldPasswordId)
+	a.Equals(t, sealErr.PasswordId, "first")
+	a.Equals(t, sealErr.Expiration.Equal(ttlNow.Add(time.Minute)), true)
+}
+
+func TestSealErrorForBadHmac(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{String: DecryptedPasswordAlt},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonBadHmac)
+	a.Equals(t, sealErr.Field, ironerrors.FieldHmac)
+	a.Equals(t, sealErr.PasswordId, "")
+	a.Equals(t, sealErr.Expiration.IsZero(), true)
+}
+
+func TestSealErrorForMalformedSeal(t *testing.T) {
+	t.Parallel()
+
+	_, err := iron.Unseal[string]("Fe26.2*first*salt", pw.UnsealRaw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonMalformed)
+	a.Equals(t, sealErr.Field, ironerrors.FieldNone)
+	a.Equals(t, err.Error(), "invalid seal (reason: malformed)")
+}
+
+func TestSealErrorForInvalidPayload(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256GCM,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	parts := strings.Split(sealed, "*")
+	parts[4] = "%" + parts[4]
+
+	_, err = iron.Unseal[string](strings.Join(parts, "*"), pw.UnsealRaw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, cfg)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonInvalidField)
+	a.Equals(t, sealErr.Field, ironerrors.FieldPayload)
+}
+
+func TestSealErrorForShortPassword(t *testing.T) {
+	t.Parallel()
+
+	_, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "first",
+			Secret: pw.Password{String: "short"},
+		},
+	}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordTooShort), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonInvalidPassword)
+	a.Equals(t, sealErr.Field, ironerrors.FieldNone)
+	a.Equals(t, sealErr.PasswordId, "first")
+}
+
+func TestSealErrorForTamperedAeadSeal(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256GCM,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id:     "first",
+			Secret: pw.Password{String: DecryptedPassword},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	parts := strings.Split(sealed, "*")
+	parts[1] = "second"
+
+	_, err = iron.Unseal[string](strings.Join(parts, "*"), pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"second": {Password: pw.Password{String: DecryptedPassword}},
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+
+	sealErr := asSealError(t, err)
+	a.Equals(t, sealErr.Reason, ironerrors.ReasonDecryptFailed)
+	a.Equals(t, sealErr.Field, ironerrors.FieldPayload)
+	a.Equals(t, sealErr.PasswordId, "second")
+}
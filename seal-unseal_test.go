@@ -1,15 +1,18 @@
 package iron_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
 	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/key"
 	"github.com/iron-auth/iron-crypto/pw"
 	a "github.com/james-elicx/go-utils/assert"
+	"github.com/james-elicx/go-utils/utils"
 )
 
 func TestWorksWithAes256cbc(t *testing.T) {
@@ -100,6 +103,439 @@ func TestWorksWithAes128ctr(t *testing.T) {
 	a.Equals(t, obj, DecryptedMessage)
 }
 
+func TestWorksWithAes256gcm(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256GCM,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+		TTL:                 100000,
+		TimestampSkewSec:    0,
+		LocalTimeOffsetMsec: 0,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.HasPrefix(sealed, "Fe26.2~aead*"), true)
+	a.Equals(t, len(strings.Split(sealed, "*")), 6)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestWorksWithChacha20poly1305(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.CHACHA20POLY1305,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, len(strings.Split(sealed, "*")), 6)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	parts := strings.Split(sealed, "*")
+	parts[1] = "tampered"
+
+	_, err = iron.Unseal[string](strings.Join(parts, "*"), pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
+func TestWorksWithXchacha20poly1305(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.XCHACHA20POLY1305,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, len(strings.Split(sealed, "*")), 6)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	parts := strings.Split(sealed, "*")
+	parts[1] = "tampered"
+
+	_, err = iron.Unseal[string](strings.Join(parts, "*"), pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
+func TestAes256gcmFailsWithTamperedHeader(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256GCM,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+		TTL:                 100000,
+		TimestampSkewSec:    0,
+		LocalTimeOffsetMsec: 0,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	parts := strings.Split(sealed, "*")
+	parts[5] = parts[5][:len(parts[5])-1] + utils.Ternary(strings.HasSuffix(parts[5], "9"), "8", "9")
+
+	_, err = iron.Unseal[string](strings.Join(parts, "*"), pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrDecrypting), true)
+}
+
+func TestAes256gcmFailsWithHmacConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256GCM,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
+
+func TestWorksWithSha512Integrity(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity: iron.SealConfigOptions{
+			Algorithm:         key.SHA512,
+			Iterations:        1,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, len(strings.Split(sealed, "*")), 8)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// a digest from a different algorithm is rejected without verifying it
+	cfg.Integrity.Algorithm = key.SHA384
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+}
+
+func TestWorksWithMemoryHardKdfs(t *testing.T) {
+	t.Parallel()
+
+	encryptionOptions := iron.DefaultEncryption
+	encryptionOptions.KDF = key.Scrypt
+	encryptionOptions.Scrypt = key.ScryptParams{N: 1024}
+
+	integrityOptions := iron.DefaultIntegrity
+	integrityOptions.KDF = key.Argon2id
+	integrityOptions.Argon2 = key.Argon2Params{Memory: 1024, Time: 1, Parallelism: 1}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: encryptionOptions,
+		Integrity:  integrityOptions,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.HasPrefix(sealed, "Fe26.2~kdf=scrypt~ikdf=argon2id*"), true)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: encryptionOptions,
+		Integrity:  integrityOptions,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// the KDF recorded in the seal must be accepted by the config
+	encryptionOptions.KDF = key.PBKDF2SHA1
+	integrityOptions.KDF = key.PBKDF2SHA256
+
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: encryptionOptions,
+		Integrity:  integrityOptions,
+	})
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+
+	// once accepted, the KDF recorded in the seal is used, even though the config changed
+	encryptionOptions.AcceptedKDFs = key.KDFs(key.Scrypt)
+	integrityOptions.AcceptedKDFs = key.KDFs(key.Argon2id)
+
+	obj, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: encryptionOptions,
+		Integrity:  integrityOptions,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestRejectsForgedKdfPrefix(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	forged := strings.Replace(sealed, "Fe26.2*", "Fe26.2~kdf=argon2id~ikdf=argon2id*", 1)
+	a.Equals(t, forged != sealed, true)
+
+	start := time.Now()
+	_, err = iron.Unseal[string](forged, pw.UnsealRaw{
+		Password: pw.Password{String: DecryptedPassword},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
+	a.Equals(t, time.Since(start) < 100*time.Millisecond, true)
+}
+
+func TestDefaultKdfKeepsHapiFormat(t *testing.T) {
+	t.Parallel()
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.HasPrefix(sealed, "Fe26.2**"), true)
+}
+
+func TestWorksWithHkdfPasswordBuffer(t *testing.T) {
+	t.Parallel()
+
+	master := []byte(DecryptedPassword)
+
+	for _, encryption := range []iron.SealConfigOptions{iron.DefaultEncryption, {Algorithm: key.AES256GCM, SaltBits: 256}} {
+		cfg := iron.SealConfig{
+			Encryption: encryption,
+			Integrity:  iron.DefaultIntegrity,
+		}
+
+		sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+			Password: pw.Password{
+				Buffer: master,
+				HKDF:   true,
+			},
+		}, cfg)
+
+		a.Equals(t, err, nil)
+		// a per-seal salt is recorded for the derived subkeys
+		a.NotEquals(t, strings.Split(sealed, "*")[2], "")
+
+		obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{
+			Password: pw.Password{
+				Buffer: master,
+				HKDF:   true,
+			},
+		}, cfg)
+
+		a.Equals(t, err, nil)
+		a.Equals(t, obj, DecryptedMessage)
+
+		// using the raw buffer as the key does not work
+		_, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+			Password: pw.Password{
+				Buffer: master,
+			},
+		}, cfg)
+
+		a.NotEquals(t, err, nil)
+	}
+}
+
+func TestUnsealUsesKeyCache(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		KeyCache:   key.NewCache(10, time.Minute),
+	}
+
+	for i := 0; i < 3; i++ {
+		obj, err := iron.Unseal[string](SealedFromNode, pw.UnsealRaw{
+			Password: pw.Password{
+				String: DecryptedPassword,
+			},
+		}, cfg)
+
+		a.Equals(t, err, nil)
+		a.Equals(t, obj, DecryptedMessage)
+	}
+
+	// integrity and encryption keys are derived once each
+	stats := cfg.KeyCache.Stats()
+	a.Equals(t, stats.Misses, uint64(2))
+	a.Equals(t, stats.Hits, uint64(4))
+	a.Equals(t, stats.Size, 2)
+}
+
+func TestKeyCacheDoesNotVerifyWithOtherPassword(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+		KeyCache:   key.NewCache(10, time.Minute),
+	}
+
+	obj, err := iron.Unseal[string](SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// the keys cached for the first password are not used for a password with the same ID
+	_, err = iron.Unseal[string](SealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPasswordAlt,
+		},
+	}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
+	a.Equals(t, cfg.KeyCache.Stats().Hits, uint64(0))
+}
+
 func TestFailsWithIncorrectPasswordId(t *testing.T) {
 	t.Parallel()
 
@@ -152,7 +588,7 @@ func TestFailsWithIncorrectPasswordId(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrBadSealHmac)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBadSealHmac), true)
 }
 
 func TestWorksWithMultiplePasswords(t *testing.T) {
@@ -360,7 +796,7 @@ func TestTTLFailsIfExpired(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrExpiredSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
 }
 
 func TestTTLWorksWithSkewDisabled(t *testing.T) {
@@ -464,3 +900,37 @@ func TestTTLWorksWithSkewDisabledAndOffsetEnabled(t *testing.T) {
 	a.Equals(t, err, nil)
 	a.Equals(t, obj, DecryptedMessage)
 }
+
+func TestTTLUsesClock(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	cfg := iron.SealConfig{
+		Encryption:       iron.DefaultEncryption,
+		Integrity:        iron.DefaultIntegrity,
+		TTL:              200,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+	password := pw.Password{
+		String: DecryptedPassword,
+	}
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{Password: password}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, strings.Split(sealed, "*")[5], "1700000000200")
+
+	fake.Advance(199 * time.Millisecond)
+
+	obj, err := iron.Unseal[string](sealed, pw.UnsealRaw{Password: password}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	fake.Advance(time.Millisecond)
+
+	_, err = iron.Unseal[string](sealed, pw.UnsealRaw{Password: password}, cfg)
+
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
@@ -1,8 +1,13 @@
 package iron
 
 import (
+	"context"
 	"time"
 
+	"github.com/iron-auth/iron-crypto/bits"
+	"github.com/iron-auth/iron-crypto/clock"
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
 	"github.com/iron-auth/iron-crypto/encryption"
 	"github.com/iron-auth/iron-crypto/key"
 	"github.com/iron-auth/iron-crypto/pw"
@@ -14,14 +19,33 @@ import (
 type SealConfigOptions struct {
 	// Algorithm to use for encryption or integrity.
 	//
-	// AES256CBC or AES128CTR for encryption. SHA256 for integrity.
+	// AES256CBC, AES128CTR, AES256GCM, CHACHA20POLY1305 or XCHACHA20POLY1305 for encryption. SHA256, SHA384 or SHA512
+	// for integrity.
+	//
+	// AEAD algorithms, such as AES256GCM and CHACHA20POLY1305, authenticate the seal themselves and skip the integrity
+	// options.
 	Algorithm key.Algorithm
-	// Number of iterations to use when deriving a key from the password.
+	// Number of iterations to use when deriving a key from the password with PBKDF2.
 	Iterations int
 	// Minimum length of the password.
 	MinPasswordLength int
 	// Number of bits to use for the random salt.
 	SaltBits int
+	// Key derivation function to use with a password.
+	//
+	// Defaults to PBKDF2SHA1 for compatibility with @hapi/iron. Any other KDF is recorded in the seal, so Unseal
+	// derives the key with the same function.
+	KDF key.KDF
+	// Other key derivation functions to accept when unsealing, such as the previous KDF while tokens sealed with it are
+	// still in circulation.
+	//
+	// Seals that record any other KDF are rejected before deriving a key, so a seal cannot choose a more expensive KDF
+	// than the config allows. The parameters in these options are used with whichever KDF the seal records.
+	AcceptedKDFs key.KDFSet
+	// Parameters to use with the Scrypt KDF.
+	Scrypt key.ScryptParams
+	// Parameters to use with the Argon2id KDF.
+	Argon2 key.Argon2Params
 }
 
 // Config options for a seal.
@@ -30,16 +54,51 @@ type SealConfig struct {
 	Encryption SealConfigOptions
 	// Integrity config options.
 	Integrity SealConfigOptions
-	// Time to live in seconds - how long the sealed message is valid for.
+	// Time to live in milliseconds - how long the sealed message is valid for, as with the ttl option in @hapi/iron.
 	//
-	// 0 means it is valid forever.
+	// 0 means it is valid forever. Ignored when TTLDuration is set.
 	TTL int
+	// Time to live as a duration - how long the sealed message is valid for.
+	//
+	// Takes precedence over TTL. Rounded up to whole milliseconds.
+	TTLDuration time.Duration
 	// Maximum skew allowed in seconds for incoming expirations.
 	//
 	// Defaults to 60 seconds. Set to -1 to disable.
 	TimestampSkewSec int
-	// Local time offset in milliseconds.
+	// Local time offset in milliseconds, added to the current time when sealing and unsealing.
 	LocalTimeOffsetMsec int
+	// Retry AES128CTR seals with the legacy AES128CFB mode when their payload cannot be decoded.
+	//
+	// Earlier versions of this library used CFB mode for AES128CTR. Enable this while tokens issued by those
+	// versions are still in circulation. Raw byte payloads are never retried, as they cannot fail to decode.
+	LegacyCFBFallback bool
+	// Cache for keys derived when unsealing, so tokens presented repeatedly do not repeat the key derivation.
+	//
+	// The cache identifies keys by password ID and a hash of the password, so it can be shared between configs.
+	KeyCache *key.Cache
+	// Codec used to marshal messages before they are sealed and unmarshal them after they are unsealed.
+	//
+	// Defaults to JSON. Seals record any other codec, and Unseal rejects seals that used a different codec, so this is
+	// the only way to use a custom codec.
+	Codec codec.Codec
+	// Compressor used to compress payloads before they are encrypted.
+	//
+	// Defaults to no compression. Seals record the compressor, so Unseal decompresses them with any registered
+	// compressor even when this is not set.
+	Compressor compress.Compressor
+	// Minimum payload size in bytes to compress. Smaller payloads are sealed uncompressed.
+	CompressThreshold int
+	// Maximum size in bytes of a decompressed payload when unsealing.
+	//
+	// Defaults to compress.DefaultMaxSize.
+	MaxDecompressedSize int
+	// Clock used to generate expirations when sealing and to check them when unsealing.
+	//
+	// Defaults to the system clock.
+	Clock clock.Clock
+	// Expectation for the registered claims when unsealing a Claims payload. Nil to only check the claims' times.
+	Claims *ClaimsExpectation
 }
 
 var (
@@ -63,9 +122,15 @@ var (
 //
 // Returns a string that can be unsealed with the same password and options.
 func Seal[T any](message T, password pw.Raw, cfg SealConfig) (string, error) {
-	now := time.Now().UnixMilli() + int64(cfg.LocalTimeOffsetMsec/1000)
+	return SealContext(context.Background(), message, password, cfg)
+}
 
-	messageStr, err := str.FromObject(message)
+// Seal a message with a password according to the options in the seal options, stopping with the context's error
+// if it is done before the key derivation, encryption or HMAC steps finish.
+//
+// Returns a string that can be unsealed with the same password and options.
+func SealContext[T any](ctx context.Context, message T, password pw.Raw, cfg SealConfig) (string, error) {
+	messageStr, codecName, err := encodePayload(issueClaims(message, cfg), codec.Or(cfg.Codec))
 	if err != nil {
 		return "", err
 	}
@@ -75,16 +140,47 @@ func Seal[T any](message T, password pw.Raw, cfg SealConfig) (string, error) {
 		return "", err
 	}
 
-	data, err := encryption.Encrypt(key.Config{
+	return seal(ctx, messageStr, codecName, pass, cfg)
+}
+
+// Seal a message string with a normalised password, recording the name of the codec used to marshal it.
+func seal(ctx context.Context, messageStr string, codecName string, pass pw.Specific, cfg SealConfig) (string, error) {
+	ttl := cfg.ttl()
+
+	return sealWithExpiration(ctx, messageStr, codecName, pass, utils.Ternary(ttl > 0, cfg.now()+ttl, 0), cfg)
+}
+
+// Seal a message string with a normalised password and a fixed expiration, instead of one from the TTL.
+func sealWithExpiration(ctx context.Context, messageStr string, codecName string, pass pw.Specific, expiration int64, cfg SealConfig) (string, error) {
+	messageStr, compression, err := compressPayload(messageStr, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.Encryption.Algorithm.IsAEAD() {
+		return sealAEAD(ctx, messageStr, encryption.SealParams{
+			KDF:         cfg.Encryption.KDF,
+			Codec:       codecName,
+			Compression: compression,
+		}, pass, expiration, cfg)
+	}
+
+	data, err := encryption.EncryptContext(ctx, key.Config{
 		Password:       pass.Encryption.String,
 		PasswordBuffer: pass.Encryption.Buffer,
+		HKDF:           pass.Encryption.HKDF,
+		Info:           key.EncryptionInfo,
+		PasswordId:     pass.Id,
 		Options: key.OptionsConfig{
 			Algorithm:         cfg.Encryption.Algorithm,
 			Iterations:        cfg.Encryption.Iterations,
 			MinPasswordLength: cfg.Encryption.MinPasswordLength,
 			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               cfg.Encryption.KDF,
+			Scrypt:            cfg.Encryption.Scrypt,
+			Argon2:            cfg.Encryption.Argon2,
 		},
-	}, messageStr)
+	}, messageStr, nil)
 
 	if err != nil {
 		return "", err
@@ -92,7 +188,6 @@ func Seal[T any](message T, password pw.Raw, cfg SealConfig) (string, error) {
 
 	b64 := str.ToBase64(data.Encrypted)
 	iv := str.ToBase64(data.Key.IV)
-	expiration := utils.Ternary(cfg.TTL > 0, now+int64(cfg.TTL), 0)
 
 	sb := encryption.SealBuilder{
 		Id:         pass.Id,
@@ -100,18 +195,101 @@ func Seal[T any](message T, password pw.Raw, cfg SealConfig) (string, error) {
 		IV:         iv,
 		B64:        b64,
 		Expiration: expiration,
+		Params: encryption.SealParams{
+			KDF:          cfg.Encryption.KDF,
+			IntegrityKDF: cfg.Integrity.KDF,
+			Codec:        codecName,
+			Compression:  compression,
+		},
 	}
 
-	sealed, err := sb.Build(key.Config{
+	sealed, err := sb.BuildContext(ctx, key.Config{
 		Password:       pass.Integrity.String,
 		PasswordBuffer: pass.Integrity.Buffer,
+		HKDF:           pass.Integrity.HKDF,
+		Info:           key.IntegrityInfo,
+		PasswordId:     pass.Id,
 		Options: key.OptionsConfig{
 			Algorithm:         cfg.Integrity.Algorithm,
 			Iterations:        cfg.Integrity.Iterations,
 			MinPasswordLength: cfg.Integrity.MinPasswordLength,
 			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               cfg.Integrity.KDF,
+			Scrypt:            cfg.Integrity.Scrypt,
+			Argon2:            cfg.Integrity.Argon2,
 		},
 	})
 
 	return sealed, err
 }
+
+// Current time in milliseconds, adjusted by the local time offset.
+func (cfg SealConfig) now() int64 {
+	return clock.Or(cfg.Clock).Now().UnixMilli() + int64(cfg.LocalTimeOffsetMsec)
+}
+
+// Check whether a seal recording the KDF can be unsealed with the options.
+func (options SealConfigOptions) acceptsKDF(kdf key.KDF) bool {
+	return kdf == options.KDF || options.AcceptedKDFs.Has(kdf)
+}
+
+// Time to live in milliseconds.
+func (cfg SealConfig) ttl() int64 {
+	if cfg.TTLDuration > 0 {
+		// round up, so a sub-millisecond duration does not become a seal that never expires
+		return (cfg.TTLDuration + time.Millisecond - 1).Milliseconds()
+	}
+
+	return int64(cfg.TTL)
+}
+
+// Time a seal made with the config lives for, from TTLDuration or TTL, or zero if seals never expire.
+func (cfg SealConfig) Lifetime() time.Duration {
+	return time.Duration(cfg.ttl()) * time.Millisecond
+}
+
+// Seal a message with an AEAD algorithm, authenticating the seal header as additional data instead of with a HMAC.
+func sealAEAD(ctx context.Context, messageStr string, params encryption.SealParams, pass pw.Specific, expiration int64, cfg SealConfig) (string, error) {
+	salt := ""
+	if (pass.Encryption.String != "" || pass.Encryption.HKDF) && cfg.Encryption.SaltBits > 0 {
+		// the salt is part of the additional data, so it has to exist before encrypting
+		newSalt, err := bits.RandomSalt(cfg.Encryption.SaltBits)
+		if err != nil {
+			return "", err
+		}
+		salt = newSalt
+	}
+
+	sb := encryption.SealBuilder{
+		Id:         pass.Id,
+		Salt:       salt,
+		Expiration: expiration,
+		Params:     params,
+	}
+
+	data, err := encryption.EncryptContext(ctx, key.Config{
+		Password:       pass.Encryption.String,
+		PasswordBuffer: pass.Encryption.Buffer,
+		HKDF:           pass.Encryption.HKDF,
+		Info:           key.EncryptionInfo,
+		PasswordId:     pass.Id,
+		Options: key.OptionsConfig{
+			Algorithm:         cfg.Encryption.Algorithm,
+			Iterations:        cfg.Encryption.Iterations,
+			MinPasswordLength: cfg.Encryption.MinPasswordLength,
+			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               cfg.Encryption.KDF,
+			Scrypt:            cfg.Encryption.Scrypt,
+			Argon2:            cfg.Encryption.Argon2,
+			Salt:              salt,
+		},
+	}, messageStr, sb.AdditionalData())
+	if err != nil {
+		return "", err
+	}
+
+	sb.IV = str.ToBase64(data.Key.IV)
+	sb.B64 = str.ToBase64(data.Encrypted)
+
+	return sb.BuildAEAD(), nil
+}
@@ -1,6 +1,7 @@
 package iron_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -66,7 +67,7 @@ func TestSealFailWithInvalidPassword(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 	a.Equals(t, sealed, "")
 }
 
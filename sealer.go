@@ -0,0 +1,177 @@
+package iron
+
+import (
+	"context"
+	"time"
+
+	"github.com/iron-auth/iron-crypto/codec"
+	"github.com/iron-auth/iron-crypto/compress"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+)
+
+// Seals and unseals messages of type T with passwords and a config that are validated once, up front.
+//
+// A Sealer is immutable after it is created, so it is safe for concurrent use from many goroutines.
+type Sealer[T any] struct {
+	cfg    SealConfig
+	seal   pw.Specific
+	unseal pw.UnsealSet
+}
+
+// Create a new sealer for the password and seal config.
+//
+// Seals are unsealed with the unseal password, or with the sealing password if the unseal password is empty.
+//
+// Returns an error if the passwords or config could not be used to seal and unseal.
+func NewSealer[T any](password pw.Raw, unsealPassword pw.UnsealRaw, cfg SealConfig) (*Sealer[T], error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return nil, err
+	}
+	if err = validatePassword(pass, cfg); err != nil {
+		return nil, err
+	}
+
+	if unsealPassword.Password.String == "" && len(unsealPassword.Password.Buffer) == 0 && len(unsealPassword.Map) == 0 {
+		unsealPassword = pw.UnsealRaw{
+			Map: map[string]pw.Raw{
+				pass.Id: {Specific: pass},
+			},
+		}
+	}
+
+	unsealSet, err := pw.NormaliseUnsealSet(unsealPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer[T]{
+		cfg:    cfg,
+		seal:   pass,
+		unseal: unsealSet,
+	}, nil
+}
+
+// Seal a message.
+//
+// Returns a string that can be unsealed with the sealer, or with Unseal using the same password and options.
+func (s *Sealer[T]) Seal(message T) (string, error) {
+	return s.SealContext(context.Background(), message)
+}
+
+// Seal a message, stopping with the context's error if it is done before the key derivation, encryption or HMAC
+// steps finish.
+func (s *Sealer[T]) SealContext(ctx context.Context, message T) (string, error) {
+	messageStr, codecName, err := encodePayload(issueClaims(message, s.cfg), codec.Or(s.cfg.Codec))
+	if err != nil {
+		return "", err
+	}
+
+	return seal(ctx, messageStr, codecName, s.seal, s.cfg)
+}
+
+// Unseal a sealed value into an object of the sealer's type.
+func (s *Sealer[T]) Unseal(sealed string) (T, error) {
+	return s.UnsealContext(context.Background(), sealed)
+}
+
+// Unseal a sealed value into an object of the sealer's type, stopping with the context's error if it is done before
+// the key derivation, HMAC or decryption steps finish.
+func (s *Sealer[T]) UnsealContext(ctx context.Context, sealed string) (T, error) {
+	return unseal[T](ctx, sealed, s.unseal.Lookup, s.cfg)
+}
+
+// Unseal a sealed value into an object of the sealer's type, also returning how long remains until the seal expires.
+//
+// The remaining lifetime is zero if the seal does not expire.
+func (s *Sealer[T]) UnsealWithRemaining(sealed string) (T, time.Duration, error) {
+	return s.UnsealWithRemainingContext(context.Background(), sealed)
+}
+
+// Unseal a sealed value into an object of the sealer's type, also returning how long remains until the seal expires,
+// stopping with the context's error if it is done before the key derivation, HMAC or decryption steps finish.
+func (s *Sealer[T]) UnsealWithRemainingContext(ctx context.Context, sealed string) (T, time.Duration, error) {
+	return unsealWithRemaining[T](ctx, sealed, s.unseal.Lookup, s.cfg)
+}
+
+// Seal a message as a rolling seal, that expires after the TTL or the maximum age, whichever is first.
+func (s *Sealer[T]) SealRolling(payload RollingPayload[T], rolling RollingConfig) (string, error) {
+	return s.SealRollingContext(context.Background(), payload, rolling)
+}
+
+// Seal a message as a rolling seal, stopping with the context's error if it is done before the key derivation,
+// encryption or HMAC steps finish.
+func (s *Sealer[T]) SealRollingContext(ctx context.Context, payload RollingPayload[T], rolling RollingConfig) (string, error) {
+	return sealRolling(ctx, payload, s.seal, s.cfg, rolling)
+}
+
+// Unseal a rolling seal, resealing it with a fresh expiration when less than the refresh fraction of the TTL remains.
+//
+// Returns the sealed value as it was, and false, when the seal does not need to be resealed yet.
+func (s *Sealer[T]) Roll(sealed string, rolling RollingConfig) (RollingPayload[T], string, bool, error) {
+	return s.RollContext(context.Background(), sealed, rolling)
+}
+
+// Unseal a rolling seal, resealing it when less than the refresh fraction of the TTL remains, stopping with the
+// context's error if it is done before the unsealing or sealing steps finish.
+func (s *Sealer[T]) RollContext(ctx context.Context, sealed string, rolling RollingConfig) (RollingPayload[T], string, bool, error) {
+	return roll[T](ctx, sealed, s.unseal.Lookup, s.seal, s.cfg, rolling)
+}
+
+// check the algorithms and key derivation options in the config can be used to seal
+func validateConfig(cfg SealConfig) error {
+	if cfg.Encryption.Algorithm.IsHmac() || cfg.Encryption.Algorithm.KeySize() == 0 {
+		return ironerrors.ErrInvalidEncryptionAlgorithm
+	}
+	if !cfg.Encryption.Algorithm.IsAEAD() && !cfg.Integrity.Algorithm.IsHmac() {
+		return ironerrors.ErrInvalidHmacAlgorithm
+	}
+
+	if cfg.Encryption.KDF.String() == "" || cfg.Integrity.KDF.String() == "" {
+		return ironerrors.ErrUnsupportedKDF
+	}
+
+	if err := codec.ValidateName(codec.Or(cfg.Codec).Name()); err != nil {
+		return err
+	}
+	if cfg.Compressor != nil {
+		if err := compress.ValidateName(cfg.Compressor.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check the sealing password can be used with the options in the config
+func validatePassword(pass pw.Specific, cfg SealConfig) error {
+	// NOTE: both keys are salted with the encryption salt bits when sealing
+	if err := validateRolePassword(pass.Encryption, cfg.Encryption, cfg.Encryption.SaltBits); err != nil {
+		return err
+	}
+	if cfg.Encryption.Algorithm.IsAEAD() {
+		return nil
+	}
+
+	return validateRolePassword(pass.Integrity, cfg.Integrity, cfg.Encryption.SaltBits)
+}
+
+func validateRolePassword(password pw.Password, options SealConfigOptions, saltBits int) error {
+	if password.String != "" && len(password.String) < options.MinPasswordLength {
+		return ironerrors.ErrPasswordTooShort
+	}
+	if password.String == "" && len(password.Buffer) < options.Algorithm.KeySize() {
+		return ironerrors.ErrPasswordBufferTooShort
+	}
+
+	if (password.String != "" || password.HKDF) && saltBits == 0 {
+		return ironerrors.ErrMissingSalt
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package iron_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func TestNewSealerFailsWithInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	password := pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}
+
+	_, err := iron.NewSealer[string](password, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: SealIntegrity,
+		Integrity:  SealIntegrity,
+	})
+	a.Equals(t, err, ironerrors.ErrInvalidEncryptionAlgorithm)
+
+	_, err = iron.NewSealer[string](password, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealEncryption,
+	})
+	a.Equals(t, err, ironerrors.ErrInvalidHmacAlgorithm)
+
+	_, err = iron.NewSealer[string](password, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm: key.AES256CBC,
+			KDF:       99,
+		},
+		Integrity: SealIntegrity,
+	})
+	a.Equals(t, err, ironerrors.ErrUnsupportedKDF)
+
+	_, err = iron.NewSealer[string](password, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES256CBC,
+			MinPasswordLength: 32,
+		},
+		Integrity: SealIntegrity,
+	})
+	a.Equals(t, err, ironerrors.ErrMissingSalt)
+}
+
+func TestNewSealerFailsWithInvalidPassword(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	_, err := iron.NewSealer[string](pw.Raw{}, pw.UnsealRaw{}, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+
+	_, err = iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: "password",
+		},
+	}, pw.UnsealRaw{}, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordTooShort), true)
+
+	_, err = iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			Buffer: []byte{1, 2, 3},
+		},
+	}, pw.UnsealRaw{}, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordBufferTooShort), true)
+
+	_, err = iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"password": {},
+		},
+	}, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
+}
+
+func TestSealerWorksWithSealingPassword(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	sealer, err := iron.NewSealer[string](pw.Raw{
+		Secret: pw.Secret{
+			Id: "password",
+			Secret: pw.Password{
+				String: DecryptedPassword,
+			},
+		},
+	}, pw.UnsealRaw{}, cfg)
+	a.Equals(t, err, nil)
+
+	sealed, err := sealer.Seal(DecryptedMessage)
+	a.Equals(t, err, nil)
+
+	obj, err := sealer.Unseal(sealed)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	// seals are compatible with the package functions
+	obj, err = iron.Unseal[string](sealed, pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"password": {
+				Password: pw.Password{
+					String: DecryptedPassword,
+				},
+			},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestSealerWorksWithUnsealPasswords(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: SealEncryption,
+		Integrity:  SealIntegrity,
+	}
+
+	sealer, err := iron.NewSealer[string](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{
+		Map: map[string]pw.Raw{
+			"passwordalt": {
+				Password: pw.Password{
+					String: DecryptedPasswordAlt,
+				},
+			},
+			"default": {
+				Password: pw.Password{
+					String: DecryptedPassword,
+				},
+			},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	obj, err := sealer.Unseal(SealedFromNode)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Secret: pw.Secret{
+			Id: "passwordalt",
+			Secret: pw.Password{
+				String: DecryptedPasswordAlt,
+			},
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	obj, err = sealer.Unseal(sealed)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+}
+
+func TestSealerIsSafeForConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	type message struct {
+		Index int
+	}
+
+	sealer, err := iron.NewSealer[message](pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, pw.UnsealRaw{}, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+	})
+	a.Equals(t, err, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			sealed, err := sealer.Seal(message{Index: i})
+			a.Equals(t, err, nil)
+
+			obj, err := sealer.Unseal(sealed)
+			a.Equals(t, err, nil)
+			a.Equals(t, obj.Index, i)
+		}(i)
+	}
+	wg.Wait()
+}
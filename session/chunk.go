@@ -0,0 +1,177 @@
+package session
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/james-elicx/go-utils/utils"
+)
+
+const (
+	// Default size in bytes of the value in each cookie chunk, leaving room for the name and attributes in the 4KB
+	// that browsers allow for a cookie.
+	DefaultChunkSize = 3800
+	// Default maximum size in bytes of a cookie's value across all of its chunks.
+	DefaultMaxSize = 32 * 1024
+)
+
+// Options for splitting a cookie's value across numbered cookies.
+type ChunkOptions struct {
+	// Size in bytes of the value in each chunk. Defaults to DefaultChunkSize.
+	ChunkSize int
+	// Maximum size in bytes of the value across all chunks. Defaults to DefaultMaxSize.
+	MaxSize int
+}
+
+func (opts ChunkOptions) chunkSize() int {
+	if opts.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+
+	return opts.ChunkSize
+}
+
+func (opts ChunkOptions) maxSize() int {
+	if opts.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+
+	return opts.MaxSize
+}
+
+func chunkName(name string, index int) string {
+	return name + "." + strconv.Itoa(index)
+}
+
+// Parse the index of a chunk from a cookie name, for a cookie with the given name.
+//
+// Returns false if the cookie is not a chunk of the named cookie.
+func parseChunkIndex(cookieName string, name string) (int, bool) {
+	if !strings.HasPrefix(cookieName, name+".") {
+		return 0, false
+	}
+	suffix := cookieName[len(name)+1:]
+
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index < 0 || strconv.Itoa(index) != suffix {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// Set a cookie on the response, splitting its value across the cookies name.0, name.1, ... if it is larger than the
+// chunk size.
+//
+// The cookie and any of its chunks sent with the request that are not part of the new value are removed, so a value
+// that shrinks does not leave stale chunks behind.
+func SetChunkedCookie(w http.ResponseWriter, r *http.Request, cookie *http.Cookie, opts ChunkOptions) error {
+	if len(cookie.Value) > opts.maxSize() {
+		return ironerrors.ErrCookieTooLarge
+	}
+
+	written := map[string]bool{}
+
+	size := opts.chunkSize()
+	if len(cookie.Value) <= size {
+		http.SetCookie(w, cookie)
+		written[cookie.Name] = true
+	} else {
+		for i, start := 0, 0; start < len(cookie.Value); i, start = i+1, start+size {
+			chunk := *cookie
+			chunk.Name = chunkName(cookie.Name, i)
+			chunk.Value = cookie.Value[start:utils.Ternary(start+size < len(cookie.Value), start+size, len(cookie.Value))]
+
+			http.SetCookie(w, &chunk)
+			written[chunk.Name] = true
+		}
+	}
+
+	removeCookies(w, r, cookie, written)
+
+	return nil
+}
+
+// Remove a cookie and any of its chunks sent with the request.
+func RemoveChunkedCookie(w http.ResponseWriter, r *http.Request, cookie *http.Cookie) {
+	removeCookies(w, r, cookie, map[string]bool{})
+
+	if _, err := r.Cookie(cookie.Name); err != nil {
+		// the cookie is removed even if the request did not send it, in case the browser still has it
+		removal := *cookie
+		removal.Value = ""
+		removal.MaxAge = -1
+
+		http.SetCookie(w, &removal)
+	}
+}
+
+// remove the cookie and its chunks that the request sent, except those that were just written
+func removeCookies(w http.ResponseWriter, r *http.Request, cookie *http.Cookie, written map[string]bool) {
+	for _, c := range r.Cookies() {
+		if written[c.Name] {
+			continue
+		}
+		if _, ok := parseChunkIndex(c.Name, cookie.Name); c.Name != cookie.Name && !ok {
+			continue
+		}
+
+		removal := *cookie
+		removal.Name = c.Name
+		removal.Value = ""
+		removal.MaxAge = -1
+
+		http.SetCookie(w, &removal)
+		written[c.Name] = true
+	}
+}
+
+// Read a cookie from the request, reassembling its value from the cookies name.0, name.1, ... if it was split into
+// chunks.
+//
+// Returns http.ErrNoCookie if the request does not have the cookie, or an error if the chunks are not contiguous, do
+// not have the same size, or are larger than the maximum size together.
+func ReadChunkedCookie(r *http.Request, name string, opts ChunkOptions) (string, error) {
+	if cookie, err := r.Cookie(name); err == nil {
+		if len(cookie.Value) > opts.maxSize() {
+			return "", ironerrors.ErrCookieTooLarge
+		}
+
+		return cookie.Value, nil
+	}
+
+	chunks := map[int]string{}
+	for _, c := range r.Cookies() {
+		index, ok := parseChunkIndex(c.Name, name)
+		if !ok {
+			continue
+		}
+
+		if _, exists := chunks[index]; exists {
+			return "", ironerrors.ErrInvalidCookieChunks
+		}
+		chunks[index] = c.Value
+	}
+
+	if len(chunks) == 0 {
+		return "", http.ErrNoCookie
+	}
+
+	var value strings.Builder
+	for i := 0; i < len(chunks); i++ {
+		chunk, ok := chunks[i]
+		// every chunk but the last is full, so all of them have the size of the first
+		if !ok || chunk == "" || (i < len(chunks)-1 && len(chunk) != len(chunks[0])) || len(chunk) > len(chunks[0]) {
+			return "", ironerrors.ErrInvalidCookieChunks
+		}
+
+		if value.Len()+len(chunk) > opts.maxSize() {
+			return "", ironerrors.ErrCookieTooLarge
+		}
+		value.WriteString(chunk)
+	}
+
+	return value.String(), nil
+}
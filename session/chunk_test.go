@@ -0,0 +1,201 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/session"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func requestWith(cookies ...*http.Cookie) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	return req
+}
+
+func setChunked(t *testing.T, req *http.Request, value string, opts session.ChunkOptions) map[string]*http.Cookie {
+	rec := httptest.NewRecorder()
+	err := session.SetChunkedCookie(rec, req, &http.Cookie{Name: "sid", Path: "/", Value: value}, opts)
+	a.Equals(t, err, nil)
+
+	written := map[string]*http.Cookie{}
+	for _, cookie := range rec.Result().Cookies() {
+		written[cookie.Name] = cookie
+	}
+
+	return written
+}
+
+func TestChunkedCookieFitsInOneCookie(t *testing.T) {
+	t.Parallel()
+
+	written := setChunked(t, requestWith(), "value", session.ChunkOptions{})
+
+	a.Equals(t, len(written), 1)
+	a.Equals(t, written["sid"].Value, "value")
+	a.Equals(t, written["sid"].Path, "/")
+
+	value, err := session.ReadChunkedCookie(requestWith(written["sid"]), "sid", session.ChunkOptions{})
+	a.Equals(t, err, nil)
+	a.Equals(t, value, "value")
+}
+
+func TestChunkedCookieSplitsAndReassembles(t *testing.T) {
+	t.Parallel()
+
+	opts := session.ChunkOptions{ChunkSize: 4}
+	written := setChunked(t, requestWith(), "abcdefghij", opts)
+
+	a.Equals(t, len(written), 3)
+	a.Equals(t, written["sid.0"].Value, "abcd")
+	a.Equals(t, written["sid.1"].Value, "efgh")
+	a.Equals(t, written["sid.2"].Value, "ij")
+	a.Equals(t, written["sid.2"].Path, "/")
+
+	value, err := session.ReadChunkedCookie(requestWith(written["sid.2"], written["sid.0"], written["sid.1"]), "sid", opts)
+	a.Equals(t, err, nil)
+	a.Equals(t, value, "abcdefghij")
+}
+
+func TestChunkedCookieRemovesStaleChunks(t *testing.T) {
+	t.Parallel()
+
+	opts := session.ChunkOptions{ChunkSize: 4}
+	req := requestWith(
+		&http.Cookie{Name: "sid.0", Value: "abcd"},
+		&http.Cookie{Name: "sid.1", Value: "efgh"},
+		&http.Cookie{Name: "sid.2", Value: "ij"},
+		&http.Cookie{Name: "other", Value: "keep"},
+		&http.Cookie{Name: "sid.x", Value: "keep"},
+	)
+
+	written := setChunked(t, req, "abcdef", opts)
+
+	a.Equals(t, len(written), 3)
+	a.Equals(t, written["sid.0"].Value, "abcd")
+	a.Equals(t, written["sid.1"].Value, "ef")
+	a.Equals(t, written["sid.2"].MaxAge, -1)
+
+	written = setChunked(t, req, "abc", opts)
+
+	a.Equals(t, len(written), 4)
+	a.Equals(t, written["sid"].Value, "abc")
+	a.Equals(t, written["sid.0"].MaxAge, -1)
+	a.Equals(t, written["sid.1"].MaxAge, -1)
+	a.Equals(t, written["sid.2"].MaxAge, -1)
+
+	written = setChunked(t, requestWith(&http.Cookie{Name: "sid", Value: "abc"}), "abcdef", opts)
+
+	a.Equals(t, len(written), 3)
+	a.Equals(t, written["sid"].MaxAge, -1)
+}
+
+func TestChunkedCookieEnforcesMaxSize(t *testing.T) {
+	t.Parallel()
+
+	opts := session.ChunkOptions{ChunkSize: 4, MaxSize: 8}
+
+	err := session.SetChunkedCookie(httptest.NewRecorder(), requestWith(), &http.Cookie{Name: "sid", Value: "abcdefghi"}, opts)
+	a.EqualsError(t, err, ironerrors.ErrCookieTooLarge)
+
+	_, err = session.ReadChunkedCookie(requestWith(
+		&http.Cookie{Name: "sid.0", Value: "abcd"},
+		&http.Cookie{Name: "sid.1", Value: "efgh"},
+		&http.Cookie{Name: "sid.2", Value: "i"},
+	), "sid", opts)
+	a.EqualsError(t, err, ironerrors.ErrCookieTooLarge)
+
+	_, err = session.ReadChunkedCookie(requestWith(&http.Cookie{Name: "sid", Value: "abcdefghi"}), "sid", opts)
+	a.EqualsError(t, err, ironerrors.ErrCookieTooLarge)
+}
+
+func TestReadChunkedCookieFailsWithInvalidChunks(t *testing.T) {
+	t.Parallel()
+
+	for _, cookies := range [][]*http.Cookie{
+		{{Name: "sid.0", Value: "abcd"}, {Name: "sid.2", Value: "ij"}},
+		{{Name: "sid.1", Value: "abcd"}},
+		{{Name: "sid.0", Value: "abcd"}, {Name: "sid.1", Value: "efg"}, {Name: "sid.2", Value: "ij"}},
+		{{Name: "sid.0", Value: "ab"}, {Name: "sid.1", Value: "cdef"}},
+		{{Name: "sid.0", Value: "abcd"}, {Name: "sid.0", Value: "efgh"}},
+	} {
+		_, err := session.ReadChunkedCookie(requestWith(cookies...), "sid", session.ChunkOptions{})
+		a.EqualsError(t, err, ironerrors.ErrInvalidCookieChunks)
+	}
+
+	_, err := session.ReadChunkedCookie(requestWith(&http.Cookie{Name: "other", Value: "abcd"}), "sid", session.ChunkOptions{})
+	a.EqualsError(t, err, http.ErrNoCookie)
+}
+
+func TestRemoveChunkedCookie(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	session.RemoveChunkedCookie(rec, requestWith(
+		&http.Cookie{Name: "sid.0", Value: "abcd"},
+		&http.Cookie{Name: "sid.1", Value: "ef"},
+	), &http.Cookie{Name: "sid", Path: "/"})
+
+	cookies := rec.Result().Cookies()
+	a.Equals(t, len(cookies), 3)
+	for _, cookie := range cookies {
+		a.Equals(t, cookie.MaxAge, -1)
+		a.Equals(t, cookie.Path, "/")
+	}
+}
+
+func TestMiddlewareChunksLargeSessions(t *testing.T) {
+	t.Parallel()
+
+	cfg := session.Config{Chunk: session.ChunkOptions{ChunkSize: 300}}
+	m := newManager[User](t, cfg)
+
+	name := strings.Repeat("a", 500)
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: name})
+	})))
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies) > 2, true)
+	for _, cookie := range cookies {
+		a.Equals(t, strings.HasPrefix(cookie.Name, session.DefaultCookieName+"."), true)
+		a.Equals(t, len(cookie.Value) <= 300, true)
+	}
+
+	res = serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		a.Equals(t, s.Get().Name, name)
+
+		s.Set(User{Name: "bob"})
+	})), cookies...)
+
+	written := map[string]*http.Cookie{}
+	for _, cookie := range res.Cookies() {
+		written[cookie.Name] = cookie
+	}
+	a.Equals(t, len(written), len(cookies)+1)
+	a.Equals(t, written[session.DefaultCookieName].MaxAge, 0)
+	a.Equals(t, written[session.DefaultCookieName+".0"].MaxAge, -1)
+}
+
+func TestMiddlewareFailsWhenSessionIsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{Chunk: session.ChunkOptions{MaxSize: 100}})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: strings.Repeat("a", 500)})
+	})))
+
+	a.Equals(t, res.StatusCode, http.StatusInternalServerError)
+	a.Equals(t, len(res.Cookies()), 0)
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	// Default name of the session cookie.
+	DefaultCookieName = "session"
+	// Default path of the session cookie.
+	DefaultCookiePath = "/"
+)
+
+// Attributes of the session cookie.
+type CookieOptions struct {
+	// Name of the cookie. Defaults to DefaultCookieName.
+	Name string
+	// Domain the cookie is sent to. Defaults to the host of the request.
+	Domain string
+	// Path the cookie is sent to. Defaults to DefaultCookiePath.
+	Path string
+	// Only send the cookie over HTTPS.
+	Secure bool
+	// Hide the cookie from scripts in the browser.
+	HttpOnly bool
+	// Whether the cookie is sent with cross-site requests.
+	SameSite http.SameSite
+}
+
+func (opts CookieOptions) name() string {
+	if opts.Name == "" {
+		return DefaultCookieName
+	}
+
+	return opts.Name
+}
+
+// Create a cookie with the options, that the browser keeps for the lifetime.
+//
+// A zero lifetime makes a cookie that is removed when the browser closes, and a negative lifetime removes the cookie.
+func (opts CookieOptions) cookie(value string, lifetime time.Duration) *http.Cookie {
+	path := opts.Path
+	if path == "" {
+		path = DefaultCookiePath
+	}
+
+	maxAge := 0
+	if lifetime < 0 {
+		maxAge = -1
+	} else if lifetime > 0 {
+		// round up, so the cookie is not removed before the seal expires
+		maxAge = int(math.Ceil(lifetime.Seconds()))
+	}
+
+	return &http.Cookie{
+		Name:     opts.name(),
+		Value:    value,
+		Domain:   opts.Domain,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   opts.Secure,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	}
+}
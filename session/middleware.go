@@ -0,0 +1,189 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock"
+	"github.com/iron-auth/iron-crypto/pw"
+)
+
+// Config options for sessions.
+type Config struct {
+	// Attributes of the session cookie.
+	Cookie CookieOptions
+	// Options for splitting the session cookie across numbered cookies when the seal is too large for one cookie.
+	Chunk ChunkOptions
+	// Options to seal the session data with. The cookie's Max-Age is the lifetime of the seal.
+	Seal iron.SealConfig
+	// Options for rolling sessions, that are resealed with a fresh expiration while they are in use. Nil to keep the
+	// expiration from when the session data was set.
+	Rolling *iron.RollingConfig
+	// Handler for errors sealing the session, called before the response is written.
+	//
+	// Defaults to a 500 Internal Server Error response.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Loads and saves sessions of type T in a sealed cookie.
+//
+// A Manager is immutable after it is created, so it is safe for concurrent use from many goroutines.
+type Manager[T any] struct {
+	cfg    Config
+	sealer *iron.Sealer[T]
+}
+
+// Create a new session manager for the password and config.
+//
+// Session cookies are unsealed with the unseal password, or with the sealing password if the unseal password is
+// empty.
+func New[T any](password pw.Raw, unsealPassword pw.UnsealRaw, cfg Config) (*Manager[T], error) {
+	sealer, err := iron.NewSealer[T](password, unsealPassword, cfg.Seal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager[T]{
+		cfg:    cfg,
+		sealer: sealer,
+	}, nil
+}
+
+// Load the session for a request from its session cookie.
+//
+// A request without a valid session cookie gets a new, empty session.
+func (m *Manager[T]) Load(r *http.Request) *Session[T] {
+	sealed, err := ReadChunkedCookie(r, m.cfg.Cookie.name(), m.cfg.Chunk)
+	if errors.Is(err, http.ErrNoCookie) {
+		return &Session[T]{isNew: true}
+	}
+
+	s := &Session[T]{}
+	if err == nil {
+		err = m.unseal(r, sealed, s)
+	}
+	if err != nil {
+		// the invalid cookie is removed in the response, unless new data is set
+		return &Session[T]{isNew: true, destroyed: true}
+	}
+
+	return s
+}
+
+// Unseal the session cookie into a session, rolling it if the session is a rolling session.
+func (m *Manager[T]) unseal(r *http.Request, sealed string, s *Session[T]) error {
+	if m.cfg.Rolling == nil {
+		data, err := m.sealer.UnsealContext(r.Context(), sealed)
+		s.data = data
+
+		return err
+	}
+
+	payload, resealed, rolled, err := m.sealer.RollContext(r.Context(), sealed, *m.cfg.Rolling)
+	if err != nil {
+		return err
+	}
+
+	s.data = payload.Data
+	s.created = payload.Created
+	if rolled {
+		s.rolled = resealed
+	}
+
+	return nil
+}
+
+// Seal the data in a session.
+func (m *Manager[T]) seal(r *http.Request, s *Session[T]) (string, error) {
+	if m.cfg.Rolling == nil {
+		return m.sealer.SealContext(r.Context(), s.data)
+	}
+
+	return m.sealer.SealRollingContext(r.Context(), iron.RollingPayload[T]{
+		Data:    s.data,
+		Created: s.created,
+	}, *m.cfg.Rolling)
+}
+
+// Time until a sealed session expires, or zero if it does not expire.
+func (m *Manager[T]) lifetime(sealed string) time.Duration {
+	if m.cfg.Rolling == nil {
+		return m.cfg.Seal.Lifetime()
+	}
+
+	// a rolling session can expire before the TTL, at its maximum age
+	info, err := iron.Inspect(sealed)
+	if err != nil || info.Expiration.IsZero() {
+		return m.cfg.Seal.Lifetime()
+	}
+
+	now := clock.Or(m.cfg.Seal.Clock).Now().Add(time.Duration(m.cfg.Seal.LocalTimeOffsetMsec) * time.Millisecond)
+
+	return info.Expiration.Sub(now)
+}
+
+// Write the session cookie for a session to the response, if the session has changed or been destroyed.
+//
+// Must be called before the response header is written.
+func (m *Manager[T]) Save(w http.ResponseWriter, r *http.Request, s *Session[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed {
+		sealed, err := m.seal(r, s)
+		if err != nil {
+			return err
+		}
+
+		if err = SetChunkedCookie(w, r, m.cfg.Cookie.cookie(sealed, m.lifetime(sealed)), m.cfg.Chunk); err != nil {
+			return err
+		}
+		s.changed = false
+		s.rolled = ""
+	} else if s.rolled != "" {
+		if err := SetChunkedCookie(w, r, m.cfg.Cookie.cookie(s.rolled, m.lifetime(s.rolled)), m.cfg.Chunk); err != nil {
+			return err
+		}
+		s.rolled = ""
+	} else if s.destroyed {
+		RemoveChunkedCookie(w, r, m.cfg.Cookie.cookie("", -1))
+		s.destroyed = false
+	}
+
+	return nil
+}
+
+// Wrap a handler so every request has a session in its context, retrieved with FromContext.
+//
+// The session cookie is saved before the handler writes the response header, or after the handler returns if it
+// does not write a response.
+func (m *Manager[T]) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := m.Load(r)
+		r = r.WithContext(newContext(r.Context(), s))
+
+		sw := &responseWriter{
+			ResponseWriter: w,
+			save: func() error {
+				return m.Save(w, r, s)
+			},
+			fail: func(err error) {
+				m.handleError(w, r, err)
+			},
+		}
+
+		next.ServeHTTP(sw, r)
+		sw.beforeWrite()
+	})
+}
+
+func (m *Manager[T]) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if m.cfg.ErrorHandler != nil {
+		m.cfg.ErrorHandler(w, r, err)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"net/http"
+)
+
+// Response writer that saves the session before the response header is written.
+type responseWriter struct {
+	http.ResponseWriter
+	save func() error
+	fail func(err error)
+
+	saved bool
+	err   error
+}
+
+// Save the session the first time it is called, writing the error response instead if the session is not saved.
+//
+// Returns the error from saving the session, so the handler's response is discarded.
+func (w *responseWriter) beforeWrite() error {
+	if !w.saved {
+		w.saved = true
+
+		if w.err = w.save(); w.err != nil {
+			w.fail(w.err)
+		}
+	}
+
+	return w.err
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.beforeWrite() != nil {
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if err := w.beforeWrite(); err != nil {
+		return 0, err
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	if w.beforeWrite() != nil {
+		return
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Retrieve the wrapped response writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,122 @@
+package session_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/session"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func rollingManager(t *testing.T, fake *clocktest.Fake, rolling iron.RollingConfig) *session.Manager[User] {
+	return newManager[User](t, session.Config{
+		Seal: iron.SealConfig{
+			Encryption:       iron.DefaultEncryption,
+			Integrity:        iron.DefaultIntegrity,
+			TTL:              60000,
+			TimestampSkewSec: -1,
+			Clock:            fake,
+		},
+		Rolling: &rolling,
+	})
+}
+
+func handle(t *testing.T, f func(s *session.Session[User])) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := session.FromContext[User](r.Context())
+		a.Equals(t, ok, true)
+
+		f(s)
+	})
+}
+
+func TestRollingSessionIsResealed(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	m := rollingManager(t, fake, iron.RollingConfig{})
+
+	res := serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		s.Set(User{Name: "alice"})
+	})))
+	cookie := res.Cookies()[0]
+	a.Equals(t, cookie.MaxAge, 60)
+
+	fake.Advance(20 * time.Second)
+	res = serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		a.Equals(t, s.Get(), User{Name: "alice"})
+	})), cookie)
+	a.Equals(t, len(res.Cookies()), 0)
+
+	fake.Advance(20 * time.Second)
+	res = serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		a.Equals(t, s.IsNew(), false)
+		a.Equals(t, s.Get(), User{Name: "alice"})
+	})), cookie)
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].MaxAge, 60)
+	a.Equals(t, cookies[0].Value != cookie.Value, true)
+
+	// the original cookie has expired, but the rolled one has not
+	fake.Advance(30 * time.Second)
+	serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		a.Equals(t, s.IsNew(), true)
+	})), cookie)
+	serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		a.Equals(t, s.Get(), User{Name: "alice"})
+	})), cookies[0])
+}
+
+func TestRollingSessionIsCappedByMaxAge(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	m := rollingManager(t, fake, iron.RollingConfig{MaxAge: 90 * time.Second})
+
+	res := serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		s.Set(User{Name: "alice"})
+	})))
+	cookie := res.Cookies()[0]
+
+	fake.Advance(40 * time.Second)
+	res = serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		// changing the data keeps the time the session was created
+		s.Set(User{Name: "bob"})
+	})), cookie)
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].MaxAge, 50)
+
+	fake.Advance(50 * time.Second)
+	res = serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		a.Equals(t, s.IsNew(), true)
+	})), cookies[0])
+
+	a.Equals(t, res.Cookies()[0].MaxAge, -1)
+}
+
+func TestRollingSessionStartsAgainAfterDestroy(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	m := rollingManager(t, fake, iron.RollingConfig{MaxAge: 90 * time.Second})
+
+	res := serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		s.Set(User{Name: "alice"})
+	})))
+	cookie := res.Cookies()[0]
+
+	fake.Advance(40 * time.Second)
+	res = serve(m.Middleware(handle(t, func(s *session.Session[User]) {
+		s.Destroy()
+		s.Set(User{Name: "bob"})
+	})), cookie)
+
+	a.Equals(t, res.Cookies()[0].MaxAge, 60)
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"sync"
+)
+
+// Session data for a single request, unsealed from the session cookie.
+//
+// A Session is safe for concurrent use by the goroutines handling its request.
+type Session[T any] struct {
+	mu        sync.Mutex
+	data      T
+	isNew     bool
+	changed   bool
+	destroyed bool
+
+	// time a rolling session was first created, in milliseconds since the Unix epoch
+	created int64
+	// rolling session resealed with a fresh expiration, to write in the response
+	rolled string
+}
+
+type contextKey struct{}
+
+// Retrieve the session data.
+func (s *Session[T]) Get() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data
+}
+
+// Replace the session data, so the session cookie is resealed with it in the response.
+func (s *Session[T]) Set(data T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = data
+	s.changed = true
+	s.destroyed = false
+}
+
+// Clear the session data, so the session cookie is removed in the response.
+func (s *Session[T]) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var empty T
+	s.data = empty
+	s.changed = false
+	s.destroyed = true
+	s.created = 0
+	s.rolled = ""
+}
+
+// Whether the request did not have a valid session cookie.
+func (s *Session[T]) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.isNew
+}
+
+// Retrieve the session from a request context created by the middleware.
+//
+// Returns false if the context does not have a session of type T.
+func FromContext[T any](ctx context.Context) (*Session[T], bool) {
+	s, ok := ctx.Value(contextKey{}).(*Session[T])
+	return s, ok
+}
+
+func newContext[T any](ctx context.Context, s *Session[T]) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
@@ -0,0 +1,263 @@
+package session_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/session"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+func newManager[T any](t *testing.T, cfg session.Config) *session.Manager[T] {
+	if cfg.Seal.Encryption.Algorithm == 0 {
+		cfg.Seal.Encryption = iron.DefaultEncryption
+		cfg.Seal.Integrity = iron.DefaultIntegrity
+	}
+
+	m, err := session.New[T](Password, pw.UnsealRaw{}, cfg)
+	a.Equals(t, err, nil)
+
+	return m
+}
+
+func serve(h http.Handler, cookies ...*http.Cookie) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec.Result()
+}
+
+func TestMiddlewareSetsAndLoadsSession(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{
+		Cookie: session.CookieOptions{
+			Name:     "sid",
+			Domain:   "example.com",
+			Path:     "/app",
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		},
+		Seal: iron.SealConfig{
+			Encryption: iron.DefaultEncryption,
+			Integrity:  iron.DefaultIntegrity,
+			TTL:        60000,
+		},
+	})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := session.FromContext[User](r.Context())
+		a.Equals(t, ok, true)
+		a.Equals(t, s.IsNew(), true)
+
+		s.Set(User{Name: "alice"})
+		_, _ = w.Write([]byte("ok"))
+	})))
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].Name, "sid")
+	a.Equals(t, cookies[0].Domain, "example.com")
+	a.Equals(t, cookies[0].Path, "/app")
+	a.Equals(t, cookies[0].MaxAge, 60)
+	a.Equals(t, cookies[0].Secure, true)
+	a.Equals(t, cookies[0].HttpOnly, true)
+	a.Equals(t, cookies[0].SameSite, http.SameSiteStrictMode)
+
+	res = serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := session.FromContext[User](r.Context())
+		a.Equals(t, ok, true)
+		a.Equals(t, s.IsNew(), false)
+		a.Equals(t, s.Get(), User{Name: "alice"})
+	})), cookies[0])
+
+	a.Equals(t, len(res.Cookies()), 0)
+}
+
+func TestMiddlewareSavesWhenHandlerDoesNotWrite(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: "bob", Admin: true})
+	})))
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].Name, session.DefaultCookieName)
+	a.Equals(t, cookies[0].Path, session.DefaultCookiePath)
+	a.Equals(t, cookies[0].MaxAge, 0)
+
+	s := m.Load(func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookies[0])
+		return req
+	}())
+	a.Equals(t, s.Get(), User{Name: "bob", Admin: true})
+}
+
+func TestMiddlewareRoundsMaxAgeUp(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{
+		Seal: iron.SealConfig{
+			Encryption:  iron.DefaultEncryption,
+			Integrity:   iron.DefaultIntegrity,
+			TTLDuration: 1500 * time.Millisecond,
+		},
+	})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: "alice"})
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	a.Equals(t, res.StatusCode, http.StatusNoContent)
+	a.Equals(t, res.Cookies()[0].MaxAge, 2)
+}
+
+func TestMiddlewareDestroysSession(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: "alice"})
+	})))
+	cookie := res.Cookies()[0]
+
+	res = serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		a.Equals(t, s.Get(), User{Name: "alice"})
+
+		s.Destroy()
+		a.Equals(t, s.Get(), User{})
+	})), cookie)
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].Name, session.DefaultCookieName)
+	a.Equals(t, cookies[0].Value, "")
+	a.Equals(t, cookies[0].MaxAge, -1)
+}
+
+func TestMiddlewareRemovesInvalidCookie(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[User](t, session.Config{})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		a.Equals(t, s.IsNew(), true)
+		a.Equals(t, s.Get(), User{})
+	})), &http.Cookie{Name: session.DefaultCookieName, Value: "Fe26.2*invalid"})
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].MaxAge, -1)
+}
+
+func TestMiddlewareStartsNewSessionWhenExpired(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(time.UnixMilli(1700000000000))
+	m := newManager[User](t, session.Config{
+		Seal: iron.SealConfig{
+			Encryption:       iron.DefaultEncryption,
+			Integrity:        iron.DefaultIntegrity,
+			TTL:              60000,
+			TimestampSkewSec: -1,
+			Clock:            fake,
+		},
+	})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		s.Set(User{Name: "alice"})
+	})))
+	cookie := res.Cookies()[0]
+
+	fake.Advance(time.Minute)
+
+	res = serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[User](r.Context())
+		a.Equals(t, s.IsNew(), true)
+
+		s.Set(User{Name: "bob"})
+	})), cookie)
+
+	cookies := res.Cookies()
+	a.Equals(t, len(cookies), 1)
+	a.Equals(t, cookies[0].MaxAge, 60)
+	a.Equals(t, cookies[0].Value != cookie.Value, true)
+}
+
+func TestMiddlewareCallsErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	var handled error
+	m := newManager[map[string]any](t, session.Config{
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusTeapot)
+		},
+	})
+
+	var writeErr error
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[map[string]any](r.Context())
+		// channels can not be marshalled to JSON
+		s.Set(map[string]any{"ch": make(chan int)})
+
+		_, writeErr = w.Write([]byte("ok"))
+	})))
+
+	a.Equals(t, res.StatusCode, http.StatusTeapot)
+	a.Equals(t, len(res.Cookies()), 0)
+	a.Equals(t, handled != nil, true)
+	a.Equals(t, errors.Is(writeErr, handled), true)
+}
+
+func TestMiddlewareDefaultErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	m := newManager[map[string]any](t, session.Config{})
+
+	res := serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, _ := session.FromContext[map[string]any](r.Context())
+		s.Set(map[string]any{"ch": make(chan int)})
+	})))
+
+	a.Equals(t, res.StatusCode, http.StatusInternalServerError)
+}
+
+func TestFromContextWithoutSession(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, ok := session.FromContext[User](req.Context())
+	a.Equals(t, ok, false)
+
+	m := newManager[User](t, session.Config{})
+	serve(m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := session.FromContext[string](r.Context())
+		a.Equals(t, ok, false)
+	})))
+}
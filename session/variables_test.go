@@ -0,0 +1,18 @@
+package session_test
+
+import (
+	"github.com/iron-auth/iron-crypto/pw"
+)
+
+var (
+	Password = pw.Raw{
+		Password: pw.Password{
+			String: "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword",
+		},
+	}
+)
+
+type User struct {
+	Name  string `json:"name"`
+	Admin bool   `json:"admin"`
+}
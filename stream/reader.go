@@ -0,0 +1,148 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/iron-auth/iron-crypto/encryption"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+// maximum length of a stream header
+const maxHeaderSize = 4096
+
+// Decrypts a stream written by a Writer, verifying each chunk before it is returned.
+//
+// Read returns an error if a chunk was tampered with, reordered or dropped, or if the stream ends before its final
+// chunk. Data from earlier chunks may already have been returned by then.
+type Reader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	nonce  *chunkNonce
+	header []byte
+
+	in   []byte
+	out  []byte
+	done bool
+	err  error
+}
+
+// Create a new reader that decrypts from r with the password.
+//
+// The stream header is read from r immediately.
+func NewReader(r io.Reader, password pw.UnsealRaw, cfg Config) (*Reader, error) {
+	br := bufio.NewReaderSize(r, maxHeaderSize)
+
+	line, err := br.ReadSlice('\n')
+	if err != nil {
+		return nil, ironerrors.ErrInvalidStreamHeader
+	}
+	headerBytes := append([]byte(nil), line...)
+
+	h, err := parseHeader(str.FromBuffer(headerBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	maxChunkSize := cfg.MaxChunkSize
+	if maxChunkSize == 0 {
+		maxChunkSize = DefaultChunkSize
+	}
+	if h.chunkSize > maxChunkSize {
+		return nil, ironerrors.ErrInvalidStreamHeader
+	}
+
+	// the header is only verified with the first chunk, so it must not choose a KDF the config does not accept
+	if h.kdf != cfg.Encryption.KDF && !cfg.Encryption.AcceptedKDFs.Has(h.kdf) {
+		return nil, ironerrors.ErrInvalidStreamHeader
+	}
+
+	pass, err := pw.NormaliseUnseal(password, h.id)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := generateKey(pass.Encryption, cfg.Encryption, h.kdf, h.salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := encryption.NewAEAD(k)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := newChunkNonce(aead, h.noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		r:      br,
+		aead:   aead,
+		nonce:  nonce,
+		header: headerBytes,
+		in:     make([]byte, h.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Read decrypted data from the stream.
+func (sr *Reader) Read(p []byte) (int, error) {
+	for len(sr.out) == 0 {
+		if sr.err != nil {
+			return 0, sr.err
+		}
+		if sr.done {
+			return 0, io.EOF
+		}
+
+		sr.err = sr.readChunk()
+	}
+
+	n := copy(p, sr.out)
+	sr.out = sr.out[n:]
+
+	return n, nil
+}
+
+// read and open the next chunk
+func (sr *Reader) readChunk() error {
+	n, err := io.ReadFull(sr.r, sr.in)
+
+	final := false
+	switch {
+	case errors.Is(err, io.EOF):
+		// every stream ends with a final chunk, even if it is empty
+		return ironerrors.ErrStreamTruncated
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		// a full chunk is final if nothing follows it
+		_, peekErr := sr.r.Peek(1)
+		if peekErr != nil && !errors.Is(peekErr, io.EOF) {
+			return peekErr
+		}
+		final = errors.Is(peekErr, io.EOF)
+	}
+
+	nonce, err := sr.nonce.next(final)
+	if err != nil {
+		return err
+	}
+
+	out, err := sr.aead.Open(sr.in[:0], nonce, sr.in[:n], sr.header)
+	if err != nil {
+		return ironerrors.ErrDecrypting
+	}
+
+	sr.out = out
+	sr.done = final
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+const (
+	// Default size in bytes of the plaintext in each chunk.
+	DefaultChunkSize = 64 * 1024
+	// Maximum size in bytes of the plaintext in each chunk.
+	MaxChunkSize = 16 * 1024 * 1024
+
+	headerPrefix string = "Fe26.2~stream"
+	kdfParam     string = "~kdf="
+
+	// the nonce ends with a 4 byte chunk counter and a 1 byte final chunk flag
+	nonceSuffixSize = 5
+)
+
+// Config options for a stream.
+type Config struct {
+	// Encryption options. The algorithm must be an AEAD algorithm, such as AES256GCM or XCHACHA20POLY1305.
+	Encryption iron.SealConfigOptions
+	// Size in bytes of the plaintext in each chunk.
+	//
+	// Defaults to DefaultChunkSize. Only used when writing, as the chunk size is recorded in the stream.
+	ChunkSize int
+	// Largest chunk size in bytes accepted when reading, so a stream header cannot make a reader allocate more.
+	//
+	// Defaults to DefaultChunkSize. Only used when reading.
+	MaxChunkSize int
+}
+
+// Header written at the start of a stream, authenticated as additional data with every chunk.
+type header struct {
+	id          string
+	salt        string
+	noncePrefix string
+	chunkSize   int
+	kdf         key.KDF
+}
+
+func (h header) String() string {
+	prefix := headerPrefix
+	if h.kdf != key.PBKDF2SHA1 {
+		prefix += kdfParam + h.kdf.String()
+	}
+
+	return prefix + "*" + h.id + "*" + h.salt + "*" + h.noncePrefix + "*" + strconv.Itoa(h.chunkSize) + "\n"
+}
+
+func parseHeader(line string) (header, error) {
+	parts := strings.Split(strings.TrimSuffix(line, "\n"), "*")
+	// every stream has a salt for its key
+	if len(parts) != 5 || !strings.HasPrefix(parts[0], headerPrefix) || parts[2] == "" {
+		return header{}, ironerrors.ErrInvalidStreamHeader
+	}
+
+	h := header{
+		id:          parts[1],
+		salt:        parts[2],
+		noncePrefix: parts[3],
+	}
+
+	if params := strings.TrimPrefix(parts[0], headerPrefix); params != "" {
+		kdf, ok := key.ParseKDF(strings.TrimPrefix(params, kdfParam))
+		if !ok || !strings.HasPrefix(params, kdfParam) {
+			return header{}, ironerrors.ErrInvalidStreamHeader
+		}
+		h.kdf = kdf
+	}
+
+	chunkSize, err := strconv.Atoi(parts[4])
+	if err != nil || chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return header{}, ironerrors.ErrInvalidStreamHeader
+	}
+	h.chunkSize = chunkSize
+
+	return h, nil
+}
+
+// Generate the key for a stream, using the salt if there is one or generating a new one otherwise.
+//
+// Password buffers are always expanded with HKDF and the stream's salt, so every stream has its own key. The random
+// nonce prefix is too short for a key to be reused safely across streams.
+func generateKey(pass pw.Password, options iron.SealConfigOptions, kdf key.KDF, salt string) (key.GeneratedKey, error) {
+	if !options.Algorithm.IsAEAD() {
+		return key.GeneratedKey{}, ironerrors.ErrInvalidEncryptionAlgorithm
+	}
+
+	return key.Generate(key.Config{
+		Password:       pass.String,
+		PasswordBuffer: pass.Buffer,
+		HKDF:           true,
+		Info:           key.EncryptionInfo,
+		Options: key.OptionsConfig{
+			Algorithm:         options.Algorithm,
+			Iterations:        options.Iterations,
+			MinPasswordLength: options.MinPasswordLength,
+			SaltBits:          options.SaltBits,
+			KDF:               kdf,
+			Scrypt:            options.Scrypt,
+			Argon2:            options.Argon2,
+			Salt:              salt,
+		},
+	})
+}
+
+// Tracks the nonce for each chunk in a stream.
+type chunkNonce struct {
+	nonce   []byte
+	counter uint32
+	// set once the counter has been used for the last possible chunk
+	exhausted bool
+}
+
+func newChunkNonce(aead cipher.AEAD, noncePrefix string) (*chunkNonce, error) {
+	prefix, err := str.FromBase64(noncePrefix)
+	if err != nil || len(prefix) != aead.NonceSize()-nonceSuffixSize {
+		return nil, ironerrors.ErrInvalidStreamHeader
+	}
+
+	return &chunkNonce{nonce: append(prefix, make([]byte, nonceSuffixSize)...)}, nil
+}
+
+// Retrieve the nonce for the next chunk.
+func (n *chunkNonce) next(final bool) ([]byte, error) {
+	if n.exhausted {
+		return nil, ironerrors.ErrStreamTooLong
+	}
+
+	suffix := n.nonce[len(n.nonce)-nonceSuffixSize:]
+	binary.BigEndian.PutUint32(suffix, n.counter)
+	suffix[4] = 0
+	if final {
+		suffix[4] = 1
+	}
+
+	if n.counter == math.MaxUint32 {
+		n.exhausted = true
+	} else {
+		n.counter++
+	}
+
+	return n.nonce, nil
+}
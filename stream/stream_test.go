@@ -0,0 +1,346 @@
+package stream_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/stream"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+const (
+	password  = "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword"
+	chunkSize = 64
+)
+
+func config(algorithm key.Algorithm) stream.Config {
+	encryption := iron.DefaultEncryption
+	encryption.Algorithm = algorithm
+
+	return stream.Config{
+		Encryption: encryption,
+		ChunkSize:  chunkSize,
+	}
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	a.Equals(t, err, nil)
+
+	return b
+}
+
+func encrypt(t *testing.T, plaintext []byte, cfg stream.Config) []byte {
+	var buf bytes.Buffer
+
+	w, err := stream.NewWriter(&buf, pw.Raw{
+		Password: pw.Password{
+			String: password,
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	// write in uneven pieces, so chunks do not line up with writes
+	for len(plaintext) > 0 {
+		n := 37
+		if n > len(plaintext) {
+			n = len(plaintext)
+		}
+
+		written, err := w.Write(plaintext[:n])
+		a.Equals(t, err, nil)
+		a.Equals(t, written, n)
+
+		plaintext = plaintext[n:]
+	}
+
+	a.Equals(t, w.Close(), nil)
+
+	return buf.Bytes()
+}
+
+func decrypt(encrypted []byte, cfg stream.Config) ([]byte, error) {
+	r, err := stream.NewReader(bytes.NewReader(encrypted), pw.UnsealRaw{
+		Password: pw.Password{
+			String: password,
+		},
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// split an encrypted stream into its header and chunks
+func split(encrypted []byte, overhead int) ([]byte, [][]byte) {
+	headerEnd := bytes.IndexByte(encrypted, '\n') + 1
+	header, body := encrypted[:headerEnd], encrypted[headerEnd:]
+
+	chunks := [][]byte{}
+	for len(body) > 0 {
+		n := chunkSize + overhead
+		if n > len(body) {
+			n = len(body)
+		}
+
+		chunks = append(chunks, body[:n])
+		body = body[n:]
+	}
+
+	return header, chunks
+}
+
+func join(header []byte, chunks ...[]byte) []byte {
+	return bytes.Join(append([][]byte{header}, chunks...), nil)
+}
+
+func TestStreamWorks(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []key.Algorithm{key.AES256GCM, key.CHACHA20POLY1305, key.XCHACHA20POLY1305} {
+		for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 1000} {
+			cfg := config(algorithm)
+			plaintext := randomBytes(t, size)
+
+			encrypted := encrypt(t, plaintext, cfg)
+
+			decrypted, err := decrypt(encrypted, cfg)
+			a.Equals(t, err, nil)
+			a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+		}
+	}
+}
+
+func TestStreamWorksWithDefaultChunkSize(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	cfg.ChunkSize = 0
+	plaintext := randomBytes(t, 2*stream.DefaultChunkSize+10)
+
+	encrypted := encrypt(t, plaintext, cfg)
+	a.Equals(t, strings.HasSuffix(strings.SplitN(string(encrypted), "\n", 2)[0], "*65536"), true)
+
+	decrypted, err := decrypt(encrypted, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+}
+
+func TestStreamLimitsChunkSizeWhenReading(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	cfg.ChunkSize = stream.DefaultChunkSize + 1
+	plaintext := randomBytes(t, 100)
+
+	encrypted := encrypt(t, plaintext, cfg)
+
+	_, err := decrypt(encrypted, config(key.AES256GCM))
+	a.EqualsError(t, err, ironerrors.ErrInvalidStreamHeader)
+
+	cfg.MaxChunkSize = cfg.ChunkSize
+
+	decrypted, err := decrypt(encrypted, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+}
+
+func TestStreamRecordsKdf(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	cfg.Encryption.KDF = key.PBKDF2SHA256
+	plaintext := randomBytes(t, 100)
+
+	encrypted := encrypt(t, plaintext, cfg)
+	a.Equals(t, strings.HasPrefix(string(encrypted), "Fe26.2~stream~kdf=pbkdf2-sha256*"), true)
+
+	decrypted, err := decrypt(encrypted, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+
+	// the kdf recorded in the stream must be accepted by the config
+	_, err = decrypt(encrypted, config(key.AES256GCM))
+	a.EqualsError(t, err, ironerrors.ErrInvalidStreamHeader)
+
+	// once accepted, the reader uses the kdf recorded in the stream
+	cfg = config(key.AES256GCM)
+	cfg.Encryption.AcceptedKDFs = key.KDFs(key.PBKDF2SHA256)
+
+	decrypted, err = decrypt(encrypted, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+}
+
+func TestStreamDerivesKeyFromPasswordBuffer(t *testing.T) {
+	t.Parallel()
+
+	buffer := randomBytes(t, 32)
+	cfg := config(key.CHACHA20POLY1305)
+	plaintext := randomBytes(t, 100)
+
+	encryptWithBuffer := func() []byte {
+		var buf bytes.Buffer
+		w, err := stream.NewWriter(&buf, pw.Raw{Password: pw.Password{Buffer: buffer}}, cfg)
+		a.Equals(t, err, nil)
+
+		_, err = w.Write(plaintext)
+		a.Equals(t, err, nil)
+		a.Equals(t, w.Close(), nil)
+
+		return buf.Bytes()
+	}
+
+	first, second := encryptWithBuffer(), encryptWithBuffer()
+	firstHeader, firstChunks := split(first, 16)
+	secondHeader, secondChunks := split(second, 16)
+
+	// each stream has its own salt, so the key is not reused even if the nonce prefix repeats
+	a.Equals(t, strings.Split(string(firstHeader), "*")[2] != "", true)
+	a.Equals(t, strings.Split(string(firstHeader), "*")[2] != strings.Split(string(secondHeader), "*")[2], true)
+	a.Equals(t, bytes.Equal(firstChunks[0], secondChunks[0]), false)
+
+	r, err := stream.NewReader(bytes.NewReader(first), pw.UnsealRaw{Password: pw.Password{Buffer: buffer}}, cfg)
+	a.Equals(t, err, nil)
+
+	decrypted, err := io.ReadAll(r)
+	a.Equals(t, err, nil)
+	a.Equals(t, bytes.Equal(decrypted, plaintext), true)
+}
+
+func TestStreamDetectsTruncation(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	encrypted := encrypt(t, randomBytes(t, 3*chunkSize+10), cfg)
+	header, chunks := split(encrypted, 16)
+	a.Equals(t, len(chunks), 4)
+
+	_, err := decrypt(join(header, chunks[:3]...), cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+
+	_, err = decrypt(encrypted[:len(encrypted)-1], cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+
+	_, err = decrypt(header, cfg)
+	a.EqualsError(t, err, ironerrors.ErrStreamTruncated)
+}
+
+func TestStreamDetectsReordering(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	encrypted := encrypt(t, randomBytes(t, 3*chunkSize+10), cfg)
+	header, chunks := split(encrypted, 16)
+
+	_, err := decrypt(join(header, chunks[1], chunks[0], chunks[2], chunks[3]), cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+
+	_, err = decrypt(join(header, chunks[0], chunks[0], chunks[2], chunks[3]), cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+}
+
+func TestStreamDetectsTampering(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	encrypted := encrypt(t, randomBytes(t, 3*chunkSize+10), cfg)
+
+	tampered := append([]byte(nil), encrypted...)
+	tampered[len(tampered)-20] ^= 1
+
+	_, err := decrypt(tampered, cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+
+	// the header is authenticated with every chunk
+	header, chunks := split(encrypted, 16)
+	tamperedHeader := bytes.Replace(header, []byte("*64\n"), []byte("*064\n"), 1)
+
+	_, err = decrypt(join(tamperedHeader, chunks...), cfg)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+}
+
+func TestStreamFailsWithIncorrectPassword(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+	encrypted := encrypt(t, randomBytes(t, 100), cfg)
+
+	r, err := stream.NewReader(bytes.NewReader(encrypted), pw.UnsealRaw{
+		Password: pw.Password{
+			String: strings.ToUpper(password),
+		},
+	}, cfg)
+	a.Equals(t, err, nil)
+
+	_, err = io.ReadAll(r)
+	a.EqualsError(t, err, ironerrors.ErrDecrypting)
+}
+
+func TestStreamFailsWithInvalidHeader(t *testing.T) {
+	t.Parallel()
+
+	cfg := config(key.AES256GCM)
+
+	for _, encrypted := range []string{
+		"",
+		"no newline",
+		"Fe26.2*id*salt*prefix*64\n",
+		"Fe26.2~stream~kdf=bcrypt*id*salt*prefix*64\n",
+		"Fe26.2~stream*id*salt*prefix*0\n",
+		"Fe26.2~stream*id*salt*prefix*99999999999\n",
+		"Fe26.2~stream*id*salt*prefix\n",
+		"Fe26.2~stream*id*salt*AAAA*64\n",
+		"Fe26.2~stream*id**AAAAAAAAAA*64\n",
+		strings.Repeat("a", 5000) + "\n",
+	} {
+		_, err := decrypt([]byte(encrypted), cfg)
+		a.EqualsError(t, err, ironerrors.ErrInvalidStreamHeader)
+	}
+}
+
+func TestStreamFailsWithInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := stream.NewWriter(io.Discard, pw.Raw{
+		Password: pw.Password{
+			String: password,
+		},
+	}, config(key.AES256CBC))
+	a.EqualsError(t, err, ironerrors.ErrInvalidEncryptionAlgorithm)
+
+	cfg := config(key.AES256GCM)
+	cfg.ChunkSize = stream.MaxChunkSize + 1
+
+	_, err = stream.NewWriter(io.Discard, pw.Raw{
+		Password: pw.Password{
+			String: password,
+		},
+	}, cfg)
+	a.EqualsError(t, err, ironerrors.ErrInvalidChunkSize)
+}
+
+func TestStreamFailsToWriteWhenClosed(t *testing.T) {
+	t.Parallel()
+
+	w, err := stream.NewWriter(io.Discard, pw.Raw{
+		Password: pw.Password{
+			String: password,
+		},
+	}, config(key.AES256GCM))
+	a.Equals(t, err, nil)
+	a.Equals(t, w.Close(), nil)
+	a.Equals(t, w.Close(), nil)
+
+	_, err = w.Write([]byte("data"))
+	a.EqualsError(t, err, ironerrors.ErrStreamClosed)
+}
@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"crypto/cipher"
+	"io"
+
+	"github.com/iron-auth/iron-crypto/encryption"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	"github.com/iron-auth/iron-crypto/str"
+)
+
+// Encrypts a stream in authenticated chunks.
+//
+// Each chunk is sealed with a nonce made from its position in the stream, and the last chunk is marked as final, so
+// a reader detects chunks that were reordered, dropped or truncated. Close must be called to write the final chunk.
+type Writer struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	nonce     *chunkNonce
+	header    []byte
+	chunkSize int
+
+	buf    []byte
+	out    []byte
+	err    error
+	closed bool
+}
+
+// Create a new writer that encrypts to w with the password.
+//
+// The stream header is written to w immediately.
+func NewWriter(w io.Writer, password pw.Raw, cfg Config) (*Writer, error) {
+	chunkSize := cfg.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < 0 || chunkSize > MaxChunkSize {
+		return nil, ironerrors.ErrInvalidChunkSize
+	}
+
+	pass, err := pw.Normalise(password)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := generateKey(pass.Encryption, cfg.Encryption, cfg.Encryption.KDF, "")
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := encryption.NewAEAD(k)
+	if err != nil {
+		return nil, err
+	}
+
+	h := header{
+		id:          pass.Id,
+		salt:        k.Salt,
+		noncePrefix: str.ToBase64(k.IV[:aead.NonceSize()-nonceSuffixSize]),
+		chunkSize:   chunkSize,
+		kdf:         cfg.Encryption.KDF,
+	}
+
+	nonce, err := newChunkNonce(aead, h.noncePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	headerBytes := str.ToBuffer(h.String())
+	if _, err = w.Write(headerBytes); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		w:         w,
+		aead:      aead,
+		nonce:     nonce,
+		header:    headerBytes,
+		chunkSize: chunkSize,
+		buf:       make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Encrypt p to the stream.
+//
+// Data is buffered until there is a full chunk, and the last chunk is only written by Close.
+func (sw *Writer) Write(p []byte) (int, error) {
+	if sw.closed {
+		return 0, ironerrors.ErrStreamClosed
+	}
+	if sw.err != nil {
+		return 0, sw.err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		// a full chunk is only written once there is more data, as the last chunk has to be marked as final
+		if len(sw.buf) == sw.chunkSize {
+			if err := sw.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		copied := copy(sw.buf[len(sw.buf):sw.chunkSize], p)
+		sw.buf = sw.buf[:len(sw.buf)+copied]
+		p = p[copied:]
+		n += copied
+	}
+
+	return n, nil
+}
+
+// Write the final chunk. Does not close the underlying writer.
+func (sw *Writer) Close() error {
+	if sw.closed {
+		return sw.err
+	}
+	sw.closed = true
+
+	if sw.err != nil {
+		return sw.err
+	}
+
+	return sw.flush(true)
+}
+
+// seal the buffered chunk and write it to the underlying writer
+func (sw *Writer) flush(final bool) error {
+	nonce, err := sw.nonce.next(final)
+	if err != nil {
+		sw.err = err
+		return err
+	}
+
+	sw.out = sw.aead.Seal(sw.out[:0], nonce, sw.buf, sw.header)
+	sw.buf = sw.buf[:0]
+
+	if _, err = sw.w.Write(sw.out); err != nil {
+		sw.err = err
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package iron_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iron-auth/iron-crypto"
+	"github.com/iron-auth/iron-crypto/clock/clocktest"
+	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/pw"
+	a "github.com/james-elicx/go-utils/assert"
+)
+
+var (
+	ttlNow = time.UnixMilli(1700000000000)
+)
+
+func sealAt(t *testing.T, cfg iron.SealConfig) string {
+	sealed, err := iron.Seal(DecryptedMessage, pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+
+	return sealed
+}
+
+func unsealAt(sealed string, cfg iron.SealConfig) (string, error) {
+	return iron.Unseal[string](sealed, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+}
+
+func TestTTLIsInMilliseconds(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       iron.DefaultEncryption,
+		Integrity:        iron.DefaultIntegrity,
+		TTL:              60000,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+
+	sealed := sealAt(t, cfg)
+	a.Equals(t, strings.Split(sealed, "*")[5], "1700000060000")
+
+	fake.Advance(time.Minute - time.Millisecond)
+	obj, err := unsealAt(sealed, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	fake.Advance(time.Millisecond)
+	_, err = unsealAt(sealed, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestTTLDurationExpiresExactly(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       iron.DefaultEncryption,
+		Integrity:        iron.DefaultIntegrity,
+		TTLDuration:      90 * time.Second,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+
+	sealed := sealAt(t, cfg)
+	a.Equals(t, strings.Split(sealed, "*")[5], "1700000090000")
+
+	fake.Advance(90*time.Second - time.Millisecond)
+	_, err := unsealAt(sealed, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Millisecond)
+	_, err = unsealAt(sealed, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestTTLDurationTakesPrecedence(t *testing.T) {
+	t.Parallel()
+
+	sealed := sealAt(t, iron.SealConfig{
+		Encryption:  iron.DefaultEncryption,
+		Integrity:   iron.DefaultIntegrity,
+		TTL:         60000,
+		TTLDuration: time.Second,
+		Clock:       clocktest.NewFake(ttlNow),
+	})
+
+	a.Equals(t, strings.Split(sealed, "*")[5], "1700000001000")
+}
+
+func TestTTLDurationRoundsUpToMilliseconds(t *testing.T) {
+	t.Parallel()
+
+	sealed := sealAt(t, iron.SealConfig{
+		Encryption:  iron.DefaultEncryption,
+		Integrity:   iron.DefaultIntegrity,
+		TTLDuration: time.Microsecond,
+		Clock:       clocktest.NewFake(ttlNow),
+	})
+
+	a.Equals(t, strings.Split(sealed, "*")[5], "1700000000001")
+}
+
+func TestTTLAppliesLocalTimeOffsetInBothDirections(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:          iron.DefaultEncryption,
+		Integrity:           iron.DefaultIntegrity,
+		TTL:                 60000,
+		TimestampSkewSec:    -1,
+		LocalTimeOffsetMsec: -100000,
+		Clock:               fake,
+	}
+
+	sealed := sealAt(t, cfg)
+	a.Equals(t, strings.Split(sealed, "*")[5], "1699999960000")
+
+	fake.Advance(time.Minute - time.Millisecond)
+	_, err := unsealAt(sealed, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Millisecond)
+	_, err = unsealAt(sealed, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestTTLAllowsDefaultSkew(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		TTL:        1000,
+		Clock:      fake,
+	}
+
+	sealed := sealAt(t, cfg)
+
+	fake.Advance(61*time.Second - time.Millisecond)
+	_, err := unsealAt(sealed, cfg)
+	a.Equals(t, err, nil)
+
+	fake.Advance(time.Millisecond)
+	_, err = unsealAt(sealed, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestTTLWorksWithExpirationFromNode(t *testing.T) {
+	t.Parallel()
+
+	fake := clocktest.NewFake(ttlNow)
+	cfg := iron.SealConfig{
+		Encryption:       iron.DefaultEncryption,
+		Integrity:        iron.DefaultIntegrity,
+		TimestampSkewSec: -1,
+		Clock:            fake,
+	}
+
+	// sealing the same way in Go gives the same expiration
+	goSealed := sealAt(t, iron.SealConfig{
+		Encryption: iron.DefaultEncryption,
+		Integrity:  iron.DefaultIntegrity,
+		TTL:        60000,
+		Clock:      fake,
+	})
+	a.Equals(t, strings.Split(goSealed, "*")[5], strings.Split(ExpiringSealedFromNode, "*")[5])
+
+	fake.Advance(time.Minute - time.Millisecond)
+	obj, err := unsealAt(ExpiringSealedFromNode, cfg)
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedMessage)
+
+	fake.Advance(time.Millisecond)
+	_, err = unsealAt(ExpiringSealedFromNode, cfg)
+	a.Equals(t, errors.Is(err, ironerrors.ErrExpiredSeal), true)
+}
+
+func TestLifetimeUsesTTLDurationOrTTL(t *testing.T) {
+	t.Parallel()
+
+	a.Equals(t, iron.SealConfig{}.Lifetime(), time.Duration(0))
+	a.Equals(t, iron.SealConfig{TTL: 60000}.Lifetime(), time.Minute)
+	a.Equals(t, iron.SealConfig{TTL: 60000, TTLDuration: time.Second}.Lifetime(), time.Second)
+	a.Equals(t, iron.SealConfig{TTLDuration: time.Microsecond}.Lifetime(), time.Millisecond)
+}
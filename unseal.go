@@ -1,39 +1,124 @@
 package iron
 
 import (
+	"context"
+	"errors"
 	"time"
 
+	"github.com/iron-auth/iron-crypto/codec"
 	"github.com/iron-auth/iron-crypto/encryption"
+	"github.com/iron-auth/iron-crypto/ironerrors"
 	"github.com/iron-auth/iron-crypto/key"
 	"github.com/iron-auth/iron-crypto/pw"
 	"github.com/iron-auth/iron-crypto/str"
+	"github.com/james-elicx/go-utils/utils"
 )
 
 // Unseal a sealed value into an object of the supplied generic type, using the password and seal options.
 //
 // The sealed value must have been sealed using the same password and seal options.
 func Unseal[T any](sealed string, password pw.UnsealRaw, cfg SealConfig) (T, error) {
+	return UnsealContext[T](context.Background(), sealed, password, cfg)
+}
+
+// Unseal a sealed value into an object of the supplied generic type, using the password and seal options, stopping
+// with the context's error if it is done before the key derivation, HMAC or decryption steps finish.
+//
+// The sealed value must have been sealed using the same password and seal options.
+func UnsealContext[T any](ctx context.Context, sealed string, password pw.UnsealRaw, cfg SealConfig) (T, error) {
+	return unseal[T](ctx, sealed, func(passwordId string) (pw.Specific, error) {
+		return pw.NormaliseUnseal(password, passwordId)
+	}, cfg)
+}
+
+// Look up the normalised password to unseal with for the password ID in a seal.
+type passwordLookup func(passwordId string) (pw.Specific, error)
+
+// Decode the decrypted payload of a seal.
+type payloadDecoder[T any] func(payload string) (T, error)
+
+// Unseal a sealed value into an object with the password found by the lookup.
+func unseal[T any](ctx context.Context, sealed string, lookup passwordLookup, cfg SealConfig) (T, error) {
+	c := codec.Or(cfg.Codec)
+
+	obj, err := unsealPayload(ctx, sealed, lookup, cfg, c.Name(), decodePayload[T](c))
+	if err != nil {
+		return obj, err
+	}
+
+	return obj, validateClaims(obj, cfg)
+}
+
+// Unseal a sealed value with the password found by the lookup, decoding the decrypted payload.
+//
+// Seals that were not marshalled with the named codec are rejected, unless the codec name is empty.
+func unsealPayload[T any](ctx context.Context, sealed string, lookup passwordLookup, cfg SealConfig, codecName string, decode payloadDecoder[T]) (T, error) {
 	var obj T
-	now := time.Now().UnixMilli() + int64(cfg.LocalTimeOffsetMsec)
+	if err := ctx.Err(); err != nil {
+		return obj, err
+	}
 
 	sb := encryption.SealBuilder{}
-	if err := sb.Parse(sealed, now, cfg.TimestampSkewSec); err != nil {
+	if err := sb.Parse(sealed, cfg.now(), cfg.TimestampSkewSec); err != nil {
 		return obj, err
 	}
 
-	pass, err := pw.NormaliseUnseal(password, sb.Id)
+	obj, err := unsealParsed(ctx, sb, lookup, cfg, codecName, decode)
+
+	return obj, withSealDetails(err, sb)
+}
+
+// Unseal a parsed seal with the password found by the lookup, decoding the decrypted payload.
+func unsealParsed[T any](ctx context.Context, sb encryption.SealBuilder, lookup passwordLookup, cfg SealConfig, codecName string, decode payloadDecoder[T]) (T, error) {
+	var obj T
+
+	// seals without a recorded codec were marshalled to JSON
+	if codecName != "" && utils.Ternary(sb.Params.Codec == "", codec.JSON.Name(), sb.Params.Codec) != codecName {
+		return obj, &ironerrors.SealError{
+			Reason: ironerrors.ReasonMalformed,
+			Field:  ironerrors.FieldPrefix,
+			Err:    ironerrors.ErrUnexpectedCodec,
+		}
+	}
+
+	// the prefix is only verified after deriving the keys, so it must not choose a KDF the config does not accept
+	if !acceptsSealKind(sb.Params, cfg) {
+		return obj, &ironerrors.SealError{
+			Reason: ironerrors.ReasonMalformed,
+			Field:  ironerrors.FieldPrefix,
+			Err:    ironerrors.ErrInvalidSeal,
+		}
+	}
+
+	decode, err := decompressPayload(decode, sb.Params.Compression, cfg)
+	if err != nil {
+		return obj, err
+	}
+
+	pass, err := lookup(sb.Id)
 	if err != nil {
 		return obj, err
 	}
 
-	err = sb.Verify(key.Config{
+	if sb.Params.AEAD {
+		return unsealAEAD(ctx, sb, pass, cfg, decode)
+	}
+
+	err = sb.VerifyContext(ctx, key.Config{
 		Password:       pass.Integrity.String,
 		PasswordBuffer: pass.Integrity.Buffer,
+		HKDF:           pass.Integrity.HKDF,
+		Info:           key.IntegrityInfo,
+		Cache:          cfg.KeyCache,
+		PasswordId:     sb.Id,
 		Options: key.OptionsConfig{
 			Algorithm:         cfg.Integrity.Algorithm,
 			Iterations:        cfg.Integrity.Iterations,
 			MinPasswordLength: cfg.Integrity.MinPasswordLength,
 			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               sb.Params.IntegrityKDF,
+			Scrypt:            cfg.Integrity.Scrypt,
+			Argon2:            cfg.Integrity.Argon2,
 			Salt:              sb.GetHmacSalt(),
 		},
 	})
@@ -41,34 +126,132 @@ func Unseal[T any](sealed string, password pw.UnsealRaw, cfg SealConfig) (T, err
 		return obj, err
 	}
 
-	encrypted, err := str.FromBase64(sb.B64)
+	encrypted, ivBytes, err := decodeSealFields(sb)
 	if err != nil {
 		return obj, err
 	}
-	ivBytes, err := str.FromBase64(sb.IV)
+
+	decryptCfg := key.Config{
+		Password:       pass.Encryption.String,
+		PasswordBuffer: pass.Encryption.Buffer,
+		HKDF:           pass.Encryption.HKDF,
+		Info:           key.EncryptionInfo,
+		Cache:          cfg.KeyCache,
+		PasswordId:     sb.Id,
+		Options: key.OptionsConfig{
+			Algorithm:         cfg.Encryption.Algorithm,
+			Iterations:        cfg.Encryption.Iterations,
+			MinPasswordLength: cfg.Encryption.MinPasswordLength,
+			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               sb.Params.KDF,
+			Scrypt:            cfg.Encryption.Scrypt,
+			Argon2:            cfg.Encryption.Argon2,
+			Salt:              sb.Salt,
+			IV:                ivBytes,
+		},
+	}
+
+	decrypted, err := encryption.DecryptContext(ctx, decryptCfg, encrypted, nil)
+	if err == nil {
+		obj, err = decode(decrypted)
+	}
+
+	// the HMAC does not cover the cipher mode, so a legacy CFB seal only shows up as an undecodable payload
+	if err != nil && ctx.Err() == nil && cfg.LegacyCFBFallback && cfg.Encryption.Algorithm == key.AES128CTR {
+		decryptCfg.Options.Algorithm = key.AES128CFB
+
+		if legacy, legacyErr := encryption.DecryptContext(ctx, decryptCfg, encrypted, nil); legacyErr == nil {
+			if legacyObj, legacyErr := decode(legacy); legacyErr == nil {
+				return legacyObj, nil
+			}
+		}
+	}
+
+	return obj, err
+}
+
+// Unseal an AEAD seal, where decrypting the payload also verifies the seal header.
+func unsealAEAD[T any](ctx context.Context, sb encryption.SealBuilder, pass pw.Specific, cfg SealConfig, decode payloadDecoder[T]) (T, error) {
+	var obj T
+
+	encrypted, ivBytes, err := decodeSealFields(sb)
 	if err != nil {
 		return obj, err
 	}
 
-	decrypted, err := encryption.Decrypt(key.Config{
+	decrypted, err := encryption.DecryptContext(ctx, key.Config{
 		Password:       pass.Encryption.String,
 		PasswordBuffer: pass.Encryption.Buffer,
+		HKDF:           pass.Encryption.HKDF,
+		Info:           key.EncryptionInfo,
+		Cache:          cfg.KeyCache,
+		PasswordId:     sb.Id,
 		Options: key.OptionsConfig{
 			Algorithm:         cfg.Encryption.Algorithm,
 			Iterations:        cfg.Encryption.Iterations,
 			MinPasswordLength: cfg.Encryption.MinPasswordLength,
 			SaltBits:          cfg.Encryption.SaltBits,
+			KDF:               sb.Params.KDF,
+			Scrypt:            cfg.Encryption.Scrypt,
+			Argon2:            cfg.Encryption.Argon2,
 			Salt:              sb.Salt,
 			IV:                ivBytes,
 		},
-	}, encrypted)
+	}, encrypted, sb.AdditionalData())
+	if err != nil {
+		return obj, err
+	}
 
-	if err == nil {
-		obj, err = str.ToObject[T](decrypted)
-		if err != nil {
-			return obj, err
+	return decode(decrypted)
+}
+
+// Decode the base64 encrypted payload and IV of a seal.
+func decodeSealFields(sb encryption.SealBuilder) ([]byte, []byte, error) {
+	encrypted, err := str.FromBase64(sb.B64)
+	if err != nil {
+		return nil, nil, &ironerrors.SealError{
+			Reason: ironerrors.ReasonInvalidField,
+			Field:  ironerrors.FieldPayload,
+			Err:    err,
 		}
 	}
 
-	return obj, err
+	iv, err := str.FromBase64(sb.IV)
+	if err != nil {
+		return nil, nil, &ironerrors.SealError{
+			Reason: ironerrors.ReasonInvalidField,
+			Field:  ironerrors.FieldIV,
+			Err:    err,
+		}
+	}
+
+	return encrypted, iv, nil
+}
+
+// Add the seal's password ID and expiration to a seal error that does not have them.
+func withSealDetails(err error, sb encryption.SealBuilder) error {
+	var sealErr *ironerrors.SealError
+	if !errors.As(err, &sealErr) {
+		return err
+	}
+
+	detailed := *sealErr
+	if detailed.PasswordId == "" {
+		detailed.PasswordId = sb.Id
+	}
+	if detailed.Expiration.IsZero() && sb.Expiration != 0 {
+		detailed.Expiration = time.UnixMilli(sb.Expiration)
+	}
+
+	return &detailed
+}
+
+// Check a seal uses the AEAD mode in the config and key derivation functions it accepts.
+func acceptsSealKind(params encryption.SealParams, cfg SealConfig) bool {
+	if params.AEAD != cfg.Encryption.Algorithm.IsAEAD() || !cfg.Encryption.acceptsKDF(params.KDF) {
+		return false
+	}
+
+	// AEAD seals do not have an integrity key
+	return params.AEAD || cfg.Integrity.acceptsKDF(params.IntegrityKDF)
 }
@@ -1,10 +1,12 @@
 package iron_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/iron-auth/iron-crypto"
 	"github.com/iron-auth/iron-crypto/ironerrors"
+	"github.com/iron-auth/iron-crypto/key"
 	"github.com/iron-auth/iron-crypto/pw"
 	a "github.com/james-elicx/go-utils/assert"
 )
@@ -24,7 +26,7 @@ func TestUnsealFailsWithNoPassword(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrPasswordRequired)
+	a.Equals(t, errors.Is(err, ironerrors.ErrPasswordRequired), true)
 }
 
 func TestUnsealFailsWithInvalidSeal(t *testing.T) {
@@ -42,7 +44,7 @@ func TestUnsealFailsWithInvalidSeal(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrInvalidSeal)
+	a.Equals(t, errors.Is(err, ironerrors.ErrInvalidSeal), true)
 }
 
 func TestUnsealFailsWithInvalidJson(t *testing.T) {
@@ -78,7 +80,7 @@ func TestUnsealFailsWithInvalidB64(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrBase64Decode)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBase64Decode), true)
 }
 
 func TestUnsealFailsWithInvalidIv(t *testing.T) {
@@ -96,7 +98,7 @@ func TestUnsealFailsWithInvalidIv(t *testing.T) {
 		LocalTimeOffsetMsec: 0,
 	})
 
-	a.Equals(t, err, ironerrors.ErrBase64Decode)
+	a.Equals(t, errors.Is(err, ironerrors.ErrBase64Decode), true)
 }
 
 func TestUnsealWorksWithValidJson(t *testing.T) {
@@ -136,3 +138,78 @@ func TestUnsealWorksWithNodeJSSeal(t *testing.T) {
 	a.Equals(t, err, nil)
 	a.Equals(t, obj, DecryptedMessage)
 }
+
+func TestUnsealWorksWithNodeJSAes128ctrSeal(t *testing.T) {
+	t.Parallel()
+
+	obj, err := iron.Unseal[string](Aes128ctrSealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES128CTR,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+		Integrity: SealIntegrity,
+	})
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedLongMessage)
+}
+
+func TestUnsealFailsWithLegacyCfbSealWithoutFallback(t *testing.T) {
+	t.Parallel()
+
+	_, err := iron.Unseal[string](Aes128cfbSealedFromGo, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES128CTR,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+		Integrity: SealIntegrity,
+	})
+
+	a.Equals(t, err, ironerrors.ErrUnmarshallingObject)
+}
+
+func TestUnsealWorksWithLegacyCfbSealWithFallback(t *testing.T) {
+	t.Parallel()
+
+	cfg := iron.SealConfig{
+		Encryption: iron.SealConfigOptions{
+			Algorithm:         key.AES128CTR,
+			Iterations:        2,
+			MinPasswordLength: 32,
+			SaltBits:          256,
+		},
+		Integrity:         SealIntegrity,
+		LegacyCFBFallback: true,
+	}
+
+	obj, err := iron.Unseal[string](Aes128cfbSealedFromGo, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedLongMessage)
+
+	// seals using real CTR mode still work with the fallback enabled
+	obj, err = iron.Unseal[string](Aes128ctrSealedFromNode, pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}, cfg)
+
+	a.Equals(t, err, nil)
+	a.Equals(t, obj, DecryptedLongMessage)
+}
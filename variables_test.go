@@ -3,12 +3,27 @@ package iron_test
 import (
 	"github.com/iron-auth/iron-crypto"
 	"github.com/iron-auth/iron-crypto/key"
+	"github.com/iron-auth/iron-crypto/pw"
 )
 
 var (
 	DecryptedPassword    = "passwordpasswordpasswordpasswordpasswordpasswordpasswordpassword"
 	DecryptedPasswordAlt = "alternativealternativealternativealternativealternativealternativealternative"
 	DecryptedMessage     = "Hello World!"
+	DecryptedLongMessage = "Hello World! This message is longer than a single AES block."
+)
+
+var (
+	SealPassword = pw.Raw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}
+	UnsealPassword = pw.UnsealRaw{
+		Password: pw.Password{
+			String: DecryptedPassword,
+		},
+	}
 )
 
 var (
@@ -41,5 +56,9 @@ var (
 	InvalidJsonSealedFromGo = "Fe26.2**cd1f3fc21662f8e50a8b55c6df349d4966d2184782c7250eb71f61b2d8a490d7*daqwINv4U_jDow1nquETpg*c0f4pPDgbGLaRLuPBVrRVA**0eb59f59358b399f503f9a6e256b98939be96681158e49af6efdf33b13832d9b*kxgs8FVxhWv9V_DO1UTyR9FuyBQKH3fbDabWyN1__Fw"
 	InvalidB64SealedFromGo  = "Fe26.2**e1da5623ed521084d1967f01297576c77ae55c632ff5d4f81c26d52378902ef0*e3epKHK1DKRFeDatD1hXrQ*fdk!**f69b2b339ee498be05acb591ab4ec635ab9bc3d3d9b17eefd7a518ac696f7d7a*M3a8CK5E4UzRXpyBWccRkH7noIyunoP3Veg9gUQl108"
 	InvalidIvSealedFromGo   = "Fe26.2**1cee2defcadcb298f2f19904c76bf55f2f4d7be6c8bf04ca70f60181a782767f*gsdg!*jDaXvJ9sI-RcaGpMhmvUIw**ff301b16779215e7803206b0119763cdd7a4415993b5605cef36e5321dd2d889*lRUixLfGW3u-4d8jUQozbHN4Ij-IMPilwr3llwah6cc"
-	ValidJsonSealedFromGo   = "Fe26.2**5c8074c7968402902cb644d2c552a416ae73d0b29af8215b7c98ecbe1c86af31*S8yjbJgU7Xgn-zjsen1TiQ*edmvCzfh3K5AAULEerrLvw**304664241a9d67c92c8589f49aeb0aa90af672c8e144ccdef4b04a4473fa1d08*xwzfk-UCjZXVNRqenqJXCuxxcXabRkaTp1WwI8nLrLc"
+	Aes128ctrSealedFromNode = "Fe26.2**6c7d7cc9cde870f585d78ebbe5a1d9b0aee269d27be55ca22f15b9635ecb8be8*u9dr7gKXn3P5NFEX7w5udg*QPxRStPusetIEp-DHWwYItFciY-JE67WsAF604pOuCv0yxgxhkfmThx_px3yPei2CQn98SIFbBKBOXl4FCo**4547c9956d7e6d076e3ec50fedff80f33c27bc6827a45bb2d60c65e1a33099bd*NZ0M1xTB4D1a2ODKyTzsVGydkTl4WNN-9KSokT2U5X8"
+	Aes128cfbSealedFromGo   = "Fe26.2**b5cbac2e10c6bf45e6f0afa8ad671dfd87bccf5a0e926f096529695cd649d844*yVDM3w4EovmH2-aZ4XtGaA*TzC_0NE1wP3c9zXmJpeTwy4bbIsfgWSdMJaKloW1XsrhMjyUzF5KOsjqw2jRrLlqIdN8_Tg_V-BpZQWSCNI**30b6b4b2c9a80e6bc511b48e343c2bdb3c0fb332eb2e176144ee70c12c527151*QOmMs6Q52XfvH2OOrX4oYYObP-lw1OwafRaVGrTxN5w"
+	// Sealed as @hapi/iron does at 1700000000000 with a ttl of 60000.
+	ExpiringSealedFromNode = "Fe26.2**efb90a34c48208fdedcf7abb9ebb6b547de881188c322d13f1b579cee2e21882*5K6TaKB8RrMlUDfO9k7QCQ*wrq5370HP6XhM_E6qiLg5w*1700000060000*5655b488a60eaa3821a32ab8962f5c4662009562bb8532ab2ee17a58ab32d24c*Pby6MPyKHkvF9eDuwX6J_8NdzzkhgyDoFDe7vmoSG1E"
+	ValidJsonSealedFromGo  = "Fe26.2**5c8074c7968402902cb644d2c552a416ae73d0b29af8215b7c98ecbe1c86af31*S8yjbJgU7Xgn-zjsen1TiQ*edmvCzfh3K5AAULEerrLvw**304664241a9d67c92c8589f49aeb0aa90af672c8e144ccdef4b04a4473fa1d08*xwzfk-UCjZXVNRqenqJXCuxxcXabRkaTp1WwI8nLrLc"
 )